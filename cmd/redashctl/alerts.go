@@ -0,0 +1,74 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package main
+
+import (
+	"time"
+)
+
+func alertsList(e *env, args []string) error {
+	flags := e.flags("alerts list")
+	if _, err := e.parse(flags, args); err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	alerts, err := c.GetAlerts()
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"ID", "NAME", "QUERY", "STATE", "LAST TRIGGERED"}}
+	for _, alert := range alerts {
+		lastTriggered := ""
+		if alert.LastTriggeredAt != nil {
+			lastTriggered = alert.LastTriggeredAt.Format(time.RFC3339)
+		}
+		t.add(alert.ID, alert.Name, alert.Query.ID, alert.State, lastTriggered)
+	}
+	return e.print(alerts, t)
+}
+
+func alertsGet(e *env, args []string) error {
+	flags := e.flags("alerts get")
+	positional, err := e.parse(flags, args, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseID("id", positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	alert, err := c.GetAlert(id)
+	if err != nil {
+		return err
+	}
+
+	return e.print(alert, fields(
+		"id", alert.ID,
+		"name", alert.Name,
+		"query", alert.Query.ID,
+		"state", alert.State,
+		"condition", alert.Options.Column+" "+alert.Options.Op+" "+cell(alert.Options.Value),
+		"rearm", alert.Rearm,
+	))
+}
@@ -0,0 +1,48 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package main
+
+import (
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// clientOptions are the global flags selecting the instance
+type clientOptions struct {
+	profile    string
+	configPath string
+	url        string
+	org        string
+	apiKey     string
+}
+
+// loadConfig resolves the client configuration. Flags win over environment
+// variables, which win over the profile of the configuration file.
+func loadConfig(options clientOptions) (*redash.Config, error) {
+	config, err := redash.LoadConfig(options.configPath, options.profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.url != "" {
+		config.RedashURI = options.url
+	}
+	if options.org != "" {
+		config.OrgSlug = options.org
+	}
+	if options.apiKey != "" {
+		config.APIKey = options.apiKey
+		config.Credentials = nil
+	}
+	return config, nil
+}
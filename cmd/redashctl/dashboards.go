@@ -0,0 +1,117 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package main
+
+import (
+	"io/ioutil"
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash/dashcode"
+)
+
+func dashboardsList(e *env, args []string) error {
+	flags := e.flags("dashboards list")
+	page := flags.Int("page", 1, "page number")
+	pageSize := flags.Int("page-size", 25, "dashboards per page")
+	if _, err := e.parse(flags, args); err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	dashboards, err := c.GetDashboards(*page, *pageSize)
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"ID", "SLUG", "NAME", "TAGS", "DRAFT", "UPDATED"}}
+	for _, dashboard := range dashboards.Results {
+		t.add(dashboard.ID, dashboard.Slug, dashboard.Name, dashboard.Tags, dashboard.IsDraft, dashboard.UpdatedAt.Format(time.RFC3339))
+	}
+	return e.print(dashboards, t)
+}
+
+func dashboardsExport(e *env, args []string) error {
+	flags := e.flags("dashboards export")
+	format := flags.String("format", dashcode.FormatYAML, "yaml or json")
+	file := flags.String("file", "", "write to a file instead of stdout")
+	positional, err := e.parse(flags, args, "slug")
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	document, err := dashcode.Export(c, positional[0])
+	if err != nil {
+		return err
+	}
+	body, err := document.Encode(*format)
+	if err != nil {
+		return err
+	}
+
+	if *file != "" {
+		return ioutil.WriteFile(*file, body, 0644)
+	}
+	_, err = e.stdout.Write(body)
+	return err
+}
+
+func dashboardsImport(e *env, args []string) error {
+	flags := e.flags("dashboards import")
+	positional, err := e.parse(flags, args, "file")
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if positional[0] == "-" {
+		body, err = ioutil.ReadAll(e.stdin)
+	} else {
+		body, err = ioutil.ReadFile(positional[0])
+	}
+	if err != nil {
+		return err
+	}
+	document, err := dashcode.Decode(body)
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	result, err := dashcode.Import(c, document)
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"CHANGE", "OBJECT"}}
+	for _, object := range result.Created {
+		t.add("created", object)
+	}
+	for _, object := range result.Updated {
+		t.add("updated", object)
+	}
+	for _, object := range result.Deleted {
+		t.add("deleted", object)
+	}
+	return e.print(result, t)
+}
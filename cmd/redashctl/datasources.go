@@ -0,0 +1,65 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package main
+
+func dataSourcesList(e *env, args []string) error {
+	flags := e.flags("datasources list")
+	if _, err := e.parse(flags, args); err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	dataSources, err := c.GetDataSources()
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"ID", "NAME", "TYPE", "PAUSED"}}
+	for _, dataSource := range *dataSources {
+		t.add(dataSource.ID, dataSource.Name, dataSource.Type, dataSource.Paused != 0)
+	}
+	return e.print(dataSources, t)
+}
+
+func dataSourcesTest(e *env, args []string) error {
+	flags := e.flags("datasources test")
+	positional, err := e.parse(flags, args, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseID("id", positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	result, err := c.TestDataSource(id)
+	if err != nil {
+		return err
+	}
+
+	if err := e.print(result, fields("ok", result.Ok, "message", result.Message)); err != nil {
+		return err
+	}
+	if !result.Ok {
+		return errTestFailed
+	}
+	return nil
+}
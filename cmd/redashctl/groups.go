@@ -0,0 +1,135 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package main
+
+import (
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+func groupsList(e *env, args []string) error {
+	flags := e.flags("groups list")
+	if _, err := e.parse(flags, args); err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	groups, err := c.GetGroups()
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"ID", "NAME", "TYPE", "PERMISSIONS"}}
+	for _, group := range *groups {
+		t.add(group.ID, group.Name, group.Type, group.Permissions)
+	}
+	return e.print(groups, t)
+}
+
+func printGroup(e *env, group *redash.Group) error {
+	return e.print(group, fields(
+		"id", group.ID,
+		"name", group.Name,
+		"type", group.Type,
+		"permissions", group.Permissions,
+	))
+}
+
+func groupsGet(e *env, args []string) error {
+	flags := e.flags("groups get")
+	positional, err := e.parse(flags, args, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseID("id", positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	group, err := c.GetGroup(id)
+	if err != nil {
+		return err
+	}
+	return printGroup(e, group)
+}
+
+func groupsCreate(e *env, args []string) error {
+	flags := e.flags("groups create")
+	positional, err := e.parse(flags, args, "name")
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	group, err := c.CreateGroup(&redash.GroupCreatePayload{Name: positional[0]})
+	if err != nil {
+		return err
+	}
+	return printGroup(e, group)
+}
+
+// membership parses the group and user IDs of add-user and remove-user
+func membership(e *env, name string, args []string) (*redash.Client, int, int, error) {
+	flags := e.flags(name)
+	positional, err := e.parse(flags, args, "group-id", "user-id")
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	groupID, err := parseID("group-id", positional[0])
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	userID, err := parseID("user-id", positional[1])
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	c, err := e.client()
+	return c, groupID, userID, err
+}
+
+func groupsAddUser(e *env, args []string) error {
+	c, groupID, userID, err := membership(e, "groups add-user", args)
+	if err != nil {
+		return err
+	}
+	if err := c.GroupAddUser(groupID, userID); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{"group_id": groupID, "user_id": userID, "member": true}
+	return e.print(result, fields("group", groupID, "user", userID, "member", true))
+}
+
+func groupsRemoveUser(e *env, args []string) error {
+	c, groupID, userID, err := membership(e, "groups remove-user", args)
+	if err != nil {
+		return err
+	}
+	if err := c.GroupRemoveUser(groupID, userID); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{"group_id": groupID, "user_id": userID, "member": false}
+	return e.print(result, fields("group", groupID, "user", userID, "member", false))
+}
@@ -0,0 +1,209 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Command redashctl manages a Redash instance from the command line
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// command is a subcommand, run parses its own flags from args
+type command struct {
+	group   string
+	name    string
+	args    string
+	summary string
+	run     func(e *env, args []string) error
+}
+
+var commands = []command{
+	{"queries", "list", "", "List queries", queriesList},
+	{"queries", "get", "<id>", "Show a query", queriesGet},
+	{"queries", "run", "<id>", "Run a query and print its result", queriesRun},
+	{"queries", "archive", "<id>", "Archive a query", queriesArchive},
+	{"dashboards", "list", "", "List dashboards", dashboardsList},
+	{"dashboards", "export", "<slug>", "Export a dashboard as YAML or JSON", dashboardsExport},
+	{"dashboards", "import", "<file>", "Create or update a dashboard from an export", dashboardsImport},
+	{"datasources", "list", "", "List data sources", dataSourcesList},
+	{"datasources", "test", "<id>", "Test the connection of a data source", dataSourcesTest},
+	{"users", "list", "", "List users", usersList},
+	{"users", "get", "<id>", "Show a user", usersGet},
+	{"users", "invite", "<name> <email>", "Invite a new user", usersInvite},
+	{"users", "disable", "<id>", "Disable a user", usersDisable},
+	{"users", "enable", "<id>", "Enable a disabled user", usersEnable},
+	{"groups", "list", "", "List groups", groupsList},
+	{"groups", "get", "<id>", "Show a group", groupsGet},
+	{"groups", "create", "<name>", "Create a group", groupsCreate},
+	{"groups", "add-user", "<group-id> <user-id>", "Add a user to a group", groupsAddUser},
+	{"groups", "remove-user", "<group-id> <user-id>", "Remove a user from a group", groupsRemoveUser},
+	{"alerts", "list", "", "List alerts", alertsList},
+	{"alerts", "get", "<id>", "Show an alert", alertsGet},
+}
+
+var (
+	// errUsage is returned for invalid command lines, after the usage has been printed
+	errUsage = errors.New("usage")
+	// errTestFailed makes a failed connection test exit with an error
+	errTestFailed = errors.New("data source test failed")
+)
+
+// env is what commands share: streams, output format and the client
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	output  string
+	options clientOptions
+	c       *redash.Client
+}
+
+// client returns the client, configured on first use so that usage errors need no configuration
+func (e *env) client() (*redash.Client, error) {
+	if e.c != nil {
+		return e.c, nil
+	}
+
+	config, err := loadConfig(e.options)
+	if err != nil {
+		return nil, err
+	}
+	e.c, err = redash.NewClient(config)
+	return e.c, err
+}
+
+// flags returns the flag set of a command
+func (e *env) flags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet("redashctl "+name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	return flags
+}
+
+// parse parses the flags of a command and checks the number of positional arguments
+func (e *env) parse(flags *flag.FlagSet, args []string, names ...string) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil, err
+		}
+		return nil, errUsage
+	}
+	if flags.NArg() != len(names) {
+		fmt.Fprintf(e.stderr, "%s expects %d argument(s): %s\n", flags.Name(), len(names), strings.Join(names, " "))
+		return nil, errUsage
+	}
+	return flags.Args(), nil
+}
+
+// parseID parses a numeric ID argument
+func parseID(name, value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number, got %q", name, value)
+	}
+	return id, nil
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: redashctl [global flags] <group> <command> [flags] [arguments]")
+	fmt.Fprintln(w, "\nGlobal flags:")
+	fmt.Fprintln(w, "  -profile name    profile of the configuration file (REDASH_PROFILE)")
+	fmt.Fprintln(w, "  -config path     configuration file (REDASH_CONFIG, default ~/.redash/config.yaml)")
+	fmt.Fprintln(w, "  -url url         Redash URL (REDASH_URL)")
+	fmt.Fprintln(w, "  -org slug        organization of a multi-org instance (REDASH_ORG)")
+	fmt.Fprintln(w, "  -api-key key     API key (REDASH_API_KEY)")
+	fmt.Fprintln(w, "  -output format   table, json or yaml (default table)")
+	fmt.Fprintln(w, "\nCommands:")
+
+	groups := map[string][]command{}
+	names := []string{}
+	for _, c := range commands {
+		if _, ok := groups[c.group]; !ok {
+			names = append(names, c.group)
+		}
+		groups[c.group] = append(groups[c.group], c)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, c := range groups[name] {
+			fmt.Fprintf(w, "  %-40s %s\n", strings.TrimSpace(c.group+" "+c.name+" "+c.args), c.summary)
+		}
+	}
+}
+
+// run executes a command line and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	flags := flag.NewFlagSet("redashctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { usage(stderr) }
+	flags.StringVar(&e.options.profile, "profile", "", "")
+	flags.StringVar(&e.options.configPath, "config", "", "")
+	flags.StringVar(&e.options.url, "url", "", "")
+	flags.StringVar(&e.options.org, "org", "", "")
+	flags.StringVar(&e.options.apiKey, "api-key", "", "")
+	flags.StringVar(&e.output, "output", outputTable, "")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if e.output != outputTable && e.output != outputJSON && e.output != outputYAML {
+		fmt.Fprintf(stderr, "unsupported output %q\n", e.output)
+		return 2
+	}
+
+	args = flags.Args()
+	if len(args) < 2 {
+		usage(stderr)
+		return 2
+	}
+
+	for _, c := range commands {
+		if c.group != args[0] || c.name != args[1] {
+			continue
+		}
+
+		err := c.run(e, args[2:])
+		switch {
+		case err == nil:
+			return 0
+		case err == flag.ErrHelp:
+			return 0
+		case err == errUsage:
+			return 2
+		}
+		fmt.Fprintf(stderr, "redashctl: %s\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stderr, "unknown command %q\n\n", strings.Join(args[:2], " "))
+	usage(stderr)
+	return 2
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,181 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// execute runs a command line against https://com.acme/ and returns the exit code and output
+func execute(args ...string) (int, string, string) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	global := []string{"-url", "https://com.acme/", "-api-key", "ApIkEyApIkEyApIkEyApIkEyApIkEy"}
+	code := run(append(global, args...), strings.NewReader(""), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestQueriesList(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries?page=1&page_size=25",
+		httpmock.NewStringResponder(200, `{"count": 1, "page": 1, "page_size": 25, "results": [
+			{"id": 1, "name": "Revenue", "data_source_id": 3, "query": "SELECT day, revenue\nFROM sales", "tags": ["finance", "daily"], "updated_at": "2022-09-20T10:00:00Z"}
+		]}`))
+
+	code, stdout, _ := execute("queries", "list")
+	assert.Equal(0, code)
+	assert.Equal("ID  NAME     DATA SOURCE  TAGS           UPDATED\n"+
+		"1   Revenue  3            finance,daily  2022-09-20T10:00:00Z\n", stdout)
+
+	httpmock.RegisterResponder("GET", "https://com.acme/acme-eu/api/queries?page=1&page_size=25",
+		httpmock.NewStringResponder(200, `{"count": 0, "page": 1, "page_size": 25, "results": []}`))
+	code, stdout, _ = execute("-org", "acme-eu", "queries", "list")
+	assert.Equal(0, code)
+	assert.Equal("ID  NAME  DATA SOURCE  TAGS  UPDATED\n", stdout)
+
+	code, stdout, _ = execute("-output", "json", "queries", "list")
+	assert.Equal(0, code)
+	assert.Contains(stdout, `"name": "Revenue"`)
+
+	code, stdout, _ = execute("-output", "yaml", "queries", "list")
+	assert.Equal(0, code)
+	assert.Contains(stdout, "count: 1\npage: 1\npage_size: 25\nResults:\n  - id: 1\n    name: Revenue\n")
+	assert.Contains(stdout, "    query: |-\n      SELECT day, revenue\n      FROM sales\n")
+}
+
+func TestQueriesRun(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/1/results",
+		httpmock.NewStringResponder(200, `{"query_result": {"id": 5, "data": {
+			"columns": [{"name": "country", "type": "string"}, {"name": "revenue", "type": "float"}],
+			"rows": [{"country": "NZ", "revenue": 12.5}, {"country": "AU", "revenue": null}]
+		}}}`))
+
+	code, stdout, _ := execute("queries", "run", "-p", "year=2022", "1")
+	assert.Equal(0, code)
+	assert.Equal("COUNTRY  REVENUE\nNZ       12.5\nAU       \n", stdout)
+
+	code, _, stderr := execute("queries", "run", "-p", "year", "1")
+	assert.Equal(2, code)
+	assert.Contains(stderr, "expected name=value")
+}
+
+func TestDataSourcesTest(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/data_sources/1/test",
+		httpmock.NewStringResponder(200, `{"message": "could not connect", "ok": false}`))
+
+	code, stdout, stderr := execute("datasources", "test", "1")
+	assert.Equal(1, code)
+	assert.Contains(stdout, "could not connect")
+	assert.Equal("redashctl: data source test failed\n", stderr)
+}
+
+func TestDashboardsExport(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	body, err := ioutil.ReadFile("../../redash/testdata/get-dashboard.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards/service-slos",
+		httpmock.NewStringResponder(200, string(body)))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources",
+		httpmock.NewStringResponder(200, `[{"id": 18, "name": "Metrics"}]`))
+
+	file := filepath.Join(t.TempDir(), "dashboard.yaml")
+	code, _, _ := execute("dashboards", "export", "-file", file, "service-slos")
+	assert.Equal(0, code)
+
+	exported, err := ioutil.ReadFile(file)
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(string(exported), "version: 1\ndashboard:\n  name: Service SLOs\n"))
+}
+
+func TestUsage(t *testing.T) {
+	assert := assert.New(t)
+
+	code, _, stderr := execute("queries", "fly")
+	assert.Equal(2, code)
+	assert.Contains(stderr, `unknown command "queries fly"`)
+	assert.Contains(stderr, "queries run <id>")
+
+	code, _, stderr = execute("queries", "get")
+	assert.Equal(2, code)
+	assert.Contains(stderr, "redashctl queries get expects 1 argument(s): id")
+
+	code, _, stderr = execute("queries", "get", "one")
+	assert.Equal(1, code)
+	assert.Equal("redashctl: id must be a number, got \"one\"\n", stderr)
+}
+
+func TestLoadConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := ioutil.WriteFile(path, []byte(`
+default_profile: staging
+profiles:
+  staging:
+    url: https://staging.acme/
+    api_key: staging-key
+  prod:
+    url: https://prod.acme/
+    api_key: prod-key
+`), 0644)
+	assert.Nil(err)
+
+	t.Setenv("REDASH_CONFIG", "")
+	t.Setenv("REDASH_URL", "")
+	t.Setenv("REDASH_API_KEY", "")
+	t.Setenv("REDASH_PROFILE", "")
+
+	config, err := loadConfig(clientOptions{configPath: path})
+	assert.Nil(err)
+	assert.Equal("https://staging.acme/", config.RedashURI)
+	assert.Equal("staging-key", config.APIKey)
+
+	config, err = loadConfig(clientOptions{configPath: path, profile: "prod"})
+	assert.Nil(err)
+	assert.Equal("https://prod.acme/", config.RedashURI)
+
+	t.Setenv("REDASH_API_KEY", "env-key")
+	config, err = loadConfig(clientOptions{configPath: path, profile: "prod"})
+	assert.Nil(err)
+	assert.Equal("env-key", config.APIKey)
+
+	config, err = loadConfig(clientOptions{configPath: path, apiKey: "flag-key"})
+	assert.Nil(err)
+	assert.Equal("flag-key", config.APIKey)
+
+	_, err = loadConfig(clientOptions{configPath: path, profile: "qa"})
+	assert.EqualError(err, path+`: profile "qa" is not defined`)
+}
@@ -0,0 +1,106 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlmirKadric/redash-client-go/redash/dashcode"
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// table is the tabular rendering of a value
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func (t *table) add(values ...interface{}) {
+	row := make([]string, len(values))
+	for i, value := range values {
+		row[i] = cell(value)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// fields renders a single object as a two column table
+func fields(pairs ...interface{}) *table {
+	t := &table{headers: []string{"FIELD", "VALUE"}}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		t.add(pairs[i], pairs[i+1])
+	}
+	return t
+}
+
+func cell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case fmt.Stringer:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		body, _ := json.Marshal(v)
+		return string(body)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// print writes value as JSON or YAML, or t for table output
+func (e *env) print(value interface{}, t *table) error {
+	switch e.output {
+	case outputJSON:
+		body, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(e.stdout, string(body))
+		return err
+	case outputYAML:
+		body, err := toYAML(value)
+		if err != nil {
+			return err
+		}
+		_, err = e.stdout.Write(body)
+		return err
+	}
+
+	writer := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// toYAML converts through JSON so the json tags of the library types apply, keeping field order
+func toYAML(value interface{}) ([]byte, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return dashcode.JSONToYAML(body)
+}
@@ -0,0 +1,156 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func queriesList(e *env, args []string) error {
+	flags := e.flags("queries list")
+	page := flags.Int("page", 1, "page number")
+	pageSize := flags.Int("page-size", 25, "queries per page")
+	if _, err := e.parse(flags, args); err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	queries, err := c.ListQueries(*page, *pageSize)
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"ID", "NAME", "DATA SOURCE", "TAGS", "UPDATED"}}
+	for _, query := range queries.Results {
+		t.add(query.ID, query.Name, query.DataSourceID, query.Tags, query.UpdatedAt.Format(time.RFC3339))
+	}
+	return e.print(queries, t)
+}
+
+func queriesGet(e *env, args []string) error {
+	flags := e.flags("queries get")
+	positional, err := e.parse(flags, args, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseID("id", positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	query, err := c.GetQuery(id)
+	if err != nil {
+		return err
+	}
+
+	visualizations := []string{}
+	for _, visualization := range query.Visualizations {
+		visualizations = append(visualizations, fmt.Sprintf("%d:%s", visualization.ID, visualization.Name))
+	}
+	return e.print(query, fields(
+		"id", query.ID,
+		"name", query.Name,
+		"description", query.Description,
+		"data source", query.DataSourceID,
+		"tags", query.Tags,
+		"draft", query.IsDraft,
+		"archived", query.IsArchived,
+		"visualizations", visualizations,
+		"query", query.Query,
+	))
+}
+
+// parameters collects repeated -p name=value flags
+type parameters map[string]interface{}
+
+func (p parameters) String() string {
+	return fmt.Sprintf("%v", map[string]interface{}(p))
+}
+
+func (p parameters) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expected name=value")
+	}
+	p[parts[0]] = parts[1]
+	return nil
+}
+
+func queriesRun(e *env, args []string) error {
+	flags := e.flags("queries run")
+	values := parameters{}
+	flags.Var(values, "p", "parameter as name=value, repeatable")
+	timeout := flags.Duration("timeout", 5*time.Minute, "how long to wait for the result")
+	positional, err := e.parse(flags, args, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseID("id", positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	result, err := c.RunQuery(id, values, *timeout)
+	if err != nil {
+		return err
+	}
+
+	t := &table{}
+	for _, column := range result.Data.Columns {
+		t.headers = append(t.headers, strings.ToUpper(column.Name))
+	}
+	for _, row := range result.Data.Rows {
+		values := []interface{}{}
+		for _, column := range result.Data.Columns {
+			values = append(values, row[column.Name])
+		}
+		t.add(values...)
+	}
+	return e.print(result, t)
+}
+
+func queriesArchive(e *env, args []string) error {
+	flags := e.flags("queries archive")
+	positional, err := e.parse(flags, args, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseID("id", positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	if err := c.ArchiveQuery(id); err != nil {
+		return err
+	}
+
+	return e.print(map[string]interface{}{"id": id, "archived": true}, fields("id", id, "archived", true))
+}
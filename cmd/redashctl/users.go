@@ -0,0 +1,154 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package main
+
+import (
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+func usersList(e *env, args []string) error {
+	flags := e.flags("users list")
+	search := flags.String("search", "", "match name or email")
+	disabled := flags.Bool("disabled", false, "list disabled users instead of active ones")
+	group := flags.String("group", "", "only members of this group")
+	if _, err := e.parse(flags, args); err != nil {
+		return err
+	}
+
+	options := &redash.UserListOptions{Search: *search, Disabled: disabled}
+	if *group != "" {
+		options.GroupNames = []string{*group}
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	users := []redash.UserListItem{}
+	err = c.EachUser(options, func(user *redash.UserListItem) error {
+		users = append(users, *user)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"ID", "NAME", "EMAIL", "GROUPS", "PENDING", "DISABLED"}}
+	for _, user := range users {
+		groups := []string{}
+		for _, group := range user.Groups {
+			groups = append(groups, group.Name)
+		}
+		t.add(user.ID, user.Name, user.Email, groups, user.IsInvitationPending, user.IsDisabled)
+	}
+	return e.print(users, t)
+}
+
+func printUser(e *env, user *redash.User) error {
+	return e.print(user, fields(
+		"id", user.ID,
+		"name", user.Name,
+		"email", user.Email,
+		"groups", user.Groups,
+		"pending", user.IsInvitationPending,
+		"disabled", user.IsDisabled,
+	))
+}
+
+func usersGet(e *env, args []string) error {
+	flags := e.flags("users get")
+	positional, err := e.parse(flags, args, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseID("id", positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	user, err := c.GetUser(id)
+	if err != nil {
+		return err
+	}
+	return printUser(e, user)
+}
+
+func usersInvite(e *env, args []string) error {
+	flags := e.flags("users invite")
+	positional, err := e.parse(flags, args, "name", "email")
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	user, err := c.CreateUser(&redash.UserCreatePayload{Name: positional[0], Email: positional[1]})
+	if err != nil {
+		return err
+	}
+	return printUser(e, user)
+}
+
+func usersDisable(e *env, args []string) error {
+	flags := e.flags("users disable")
+	positional, err := e.parse(flags, args, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseID("id", positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	if err := c.DisableUser(id); err != nil {
+		return err
+	}
+	user, err := c.GetUser(id)
+	if err != nil {
+		return err
+	}
+	return printUser(e, user)
+}
+
+func usersEnable(e *env, args []string) error {
+	flags := e.flags("users enable")
+	positional, err := e.parse(flags, args, "id")
+	if err != nil {
+		return err
+	}
+	id, err := parseID("id", positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	user, err := c.EnableUser(id)
+	if err != nil {
+		return err
+	}
+	return printUser(e, user)
+}
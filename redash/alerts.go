@@ -0,0 +1,161 @@
+package redash
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Alert states reported by Redash
+const (
+	AlertStateOK        = "ok"
+	AlertStateTriggered = "triggered"
+	AlertStateUnknown   = "unknown"
+)
+
+// Alert object structure from Redash's /api/alerts/<ID> endpoint
+type Alert struct {
+	// Base Data
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+
+	// Options
+	Options AlertOptions `json:"options"`
+	Rearm   int          `json:"rearm"`
+
+	// State
+	State           string     `json:"state"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at"`
+
+	// References
+	Query Query `json:"query"`
+	User  User  `json:"user"`
+
+	// Timestamps
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AlertOptions compares Column of the query result to Value using Op (such as ">" or "==")
+type AlertOptions struct {
+	Column        string      `json:"column"`
+	Op            string      `json:"op"`
+	Value         interface{} `json:"value"`
+	CustomSubject string      `json:"custom_subject,omitempty"`
+	CustomBody    string      `json:"custom_body,omitempty"`
+	Muted         bool        `json:"muted,omitempty"`
+}
+
+// AlertCreatePayload structure for creating an alert
+type AlertCreatePayload struct {
+	Name    string       `json:"name"`
+	QueryID int          `json:"query_id"`
+	Options AlertOptions `json:"options"`
+	Rearm   int          `json:"rearm"`
+}
+
+// AlertUpdatePayload structure for updating an alert
+type AlertUpdatePayload struct {
+	Name    string       `json:"name"`
+	Options AlertOptions `json:"options"`
+	Rearm   int          `json:"rearm"`
+}
+
+// GetAlerts returns every Redash alert
+func (c *Client) GetAlerts() ([]Alert, error) {
+	path := "/api/alerts"
+
+	queryParams := url.Values{}
+	response, err := c.get(path, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	alerts := []Alert{}
+	err = json.NewDecoder(response.Body).Decode(&alerts)
+	if err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+// GetAlert returns a specific Redash alert by its ID
+func (c *Client) GetAlert(id int) (*Alert, error) {
+	path := "/api/alerts/" + strconv.Itoa(id)
+
+	queryParams := url.Values{}
+	response, err := c.get(path, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	alert := new(Alert)
+	err = json.NewDecoder(response.Body).Decode(alert)
+	if err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// CreateAlert creates a new Redash alert
+func (c *Client) CreateAlert(alert *AlertCreatePayload) (*Alert, error) {
+	path := "/api/alerts"
+
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return nil, err
+	}
+
+	queryParams := url.Values{}
+	response, err := c.post(path, string(payload), queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	result := new(Alert)
+	err = json.NewDecoder(response.Body).Decode(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateAlert updates an existing Redash alert
+func (c *Client) UpdateAlert(id int, alert *AlertUpdatePayload) (*Alert, error) {
+	path := "/api/alerts/" + strconv.Itoa(id)
+
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return nil, err
+	}
+
+	queryParams := url.Values{}
+	response, err := c.post(path, string(payload), queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	result := new(Alert)
+	err = json.NewDecoder(response.Body).Decode(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteAlert deletes a Redash alert
+func (c *Client) DeleteAlert(id int) error {
+	path := "/api/alerts/" + strconv.Itoa(id)
+
+	_, err := c.delete(path, url.Values{})
+	return err
+}
@@ -0,0 +1,114 @@
+package redash
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const alertBody = `{
+	"id": 12,
+	"name": "Revenue dropped",
+	"options": {"column": "revenue", "op": "<", "value": 1000},
+	"rearm": 3600,
+	"state": "triggered",
+	"last_triggered_at": "2022-09-20T10:00:00Z",
+	"query": {"id": 1, "name": "Revenue"},
+	"user": {"id": 2, "name": "Jane"}
+}`
+
+func TestGetAlerts(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/alerts",
+		httpmock.NewStringResponder(200, "["+alertBody+"]"))
+
+	alerts, err := c.GetAlerts()
+	assert.Nil(err)
+	assert.Equal(1, len(alerts))
+	assert.Equal("Revenue dropped", alerts[0].Name)
+	assert.Equal(AlertStateTriggered, alerts[0].State)
+	assert.Equal(1, alerts[0].Query.ID)
+}
+
+func TestGetAlert(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/alerts/12",
+		httpmock.NewStringResponder(200, alertBody))
+
+	alert, err := c.GetAlert(12)
+	assert.Nil(err)
+	assert.Equal("revenue", alert.Options.Column)
+	assert.Equal("<", alert.Options.Op)
+	assert.Equal(float64(1000), alert.Options.Value)
+	assert.Equal(3600, alert.Rearm)
+	assert.Equal(2022, alert.LastTriggeredAt.Year())
+}
+
+func TestCreateAlert(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/alerts",
+		func(req *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(req.Body)
+			payload := map[string]interface{}{}
+			_ = json.Unmarshal(body, &payload)
+			assert.Equal(float64(1), payload["query_id"])
+			assert.Equal("<", payload["options"].(map[string]interface{})["op"])
+			return httpmock.NewStringResponse(200, alertBody), nil
+		})
+
+	alert, err := c.CreateAlert(&AlertCreatePayload{
+		Name:    "Revenue dropped",
+		QueryID: 1,
+		Options: AlertOptions{Column: "revenue", Op: "<", Value: 1000},
+		Rearm:   3600,
+	})
+	assert.Nil(err)
+	assert.Equal(12, alert.ID)
+}
+
+func TestUpdateAlert(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/alerts/12",
+		httpmock.NewStringResponder(200, alertBody))
+
+	alert, err := c.UpdateAlert(12, &AlertUpdatePayload{Name: "Revenue dropped", Options: AlertOptions{Column: "revenue", Op: "<", Value: 1000}})
+	assert.Nil(err)
+	assert.Equal(12, alert.ID)
+}
+
+func TestDeleteAlert(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/alerts/12",
+		httpmock.NewStringResponder(200, ``))
+
+	assert.Nil(c.DeleteAlert(12))
+}
@@ -0,0 +1,194 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Package backup saves everything an API key can read from a Redash organization to a
+// versioned archive, either a directory of JSON files or a gzipped tarball of the same
+// files, and restores it into another instance. Data source secrets, query API keys and
+// dashboard share links are never written, but the archive still holds every query's text
+// and every user's email, so it is only readable by its owner.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// ArchiveVersion is the version of the archive format written by Backup
+const ArchiveVersion = 1
+
+// Manifest describes an archive
+type Manifest struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Counts    map[string]int `json:"counts"`
+}
+
+// Archive is a backup of an organization
+type Archive struct {
+	Manifest    Manifest
+	DataSources []redash.DataSource
+	Groups      []redash.Group
+	Users       []redash.UserListItem
+	Queries     []redash.Query
+	Dashboards  []redash.Dashboard
+}
+
+// archiveFile is a file of the archive and the part of the archive it holds
+type archiveFile struct {
+	name  string
+	value interface{}
+}
+
+func (a *Archive) files() []archiveFile {
+	return []archiveFile{
+		{"manifest.json", &a.Manifest},
+		{"data_sources.json", &a.DataSources},
+		{"groups.json", &a.Groups},
+		{"users.json", &a.Users},
+		{"queries.json", &a.Queries},
+		{"dashboards.json", &a.Dashboards},
+	}
+}
+
+func (a *Archive) count() {
+	a.Manifest.Counts = map[string]int{
+		"data_sources": len(a.DataSources),
+		"groups":       len(a.Groups),
+		"users":        len(a.Users),
+		"queries":      len(a.Queries),
+		"dashboards":   len(a.Dashboards),
+	}
+}
+
+func (a *Archive) checkVersion() error {
+	if a.Manifest.Version != ArchiveVersion {
+		return fmt.Errorf("unsupported archive version %d", a.Manifest.Version)
+	}
+	return nil
+}
+
+// WriteDir writes the archive as JSON files into dir, which is created if needed
+func (a *Archive) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	for _, file := range a.files() {
+		body, err := json.MarshalIndent(file.value, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, file.name), append(body, '\n'), 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadDir reads an archive written by WriteDir
+func ReadDir(dir string) (*Archive, error) {
+	archive := &Archive{}
+	for _, file := range archive.files() {
+		body, err := ioutil.ReadFile(filepath.Join(dir, file.name))
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(body, file.value); err != nil {
+			return nil, fmt.Errorf("%s: %s", file.name, err)
+		}
+	}
+
+	return archive, archive.checkVersion()
+}
+
+// WriteTar writes the archive as a gzipped tarball of the files written by WriteDir
+func (a *Archive) WriteTar(w io.Writer) error {
+	compressed := gzip.NewWriter(w)
+	tarball := tar.NewWriter(compressed)
+
+	for _, file := range a.files() {
+		body, err := json.MarshalIndent(file.value, "", "  ")
+		if err != nil {
+			return err
+		}
+		body = append(body, '\n')
+
+		err = tarball.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    0600,
+			Size:    int64(len(body)),
+			ModTime: a.Manifest.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := tarball.Write(body); err != nil {
+			return err
+		}
+	}
+
+	if err := tarball.Close(); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
+
+// ReadTar reads an archive written by WriteTar
+func ReadTar(r io.Reader) (*Archive, error) {
+	compressed, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	tarball := tar.NewReader(compressed)
+
+	archive := &Archive{}
+	values := map[string]interface{}{}
+	for _, file := range archive.files() {
+		values[file.name] = file.value
+	}
+
+	for {
+		header, err := tarball.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := values[header.Name]
+		if !ok {
+			continue
+		}
+		if err := json.NewDecoder(tarball).Decode(value); err != nil {
+			return nil, fmt.Errorf("%s: %s", header.Name, err)
+		}
+		delete(values, header.Name)
+	}
+
+	for name := range values {
+		return nil, fmt.Errorf("archive has no %s", name)
+	}
+
+	return archive, archive.checkVersion()
+}
@@ -0,0 +1,132 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package backup
+
+import (
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/AlmirKadric/redash-client-go/redash/migrate"
+)
+
+const pageSize = 100
+
+// Backup reads every data source, group, user, query and dashboard of the organization.
+// Secret data source options are replaced by migrate.MaskedSecret, archived queries
+// and dashboards are left out.
+func Backup(client *redash.Client) (*Archive, error) {
+	archive := &Archive{Manifest: Manifest{Version: ArchiveVersion, CreatedAt: time.Now().UTC()}}
+
+	if err := backupDataSources(client, archive); err != nil {
+		return nil, err
+	}
+
+	groups, err := client.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+	archive.Groups = *groups
+
+	// Active and disabled users are listed separately
+	disabled := true
+	for _, options := range []*redash.UserListOptions{{}, {Disabled: &disabled}} {
+		err := client.EachUser(options, func(user *redash.UserListItem) error {
+			archive.Users = append(archive.Users, *user)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for page := 1; ; page++ {
+		queries, err := client.ListQueries(page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range queries.Results {
+			query, err := client.GetQuery(item.ID)
+			if err != nil {
+				return nil, err
+			}
+			// Query API keys grant access to results without a user key
+			query.APIKey = ""
+			archive.Queries = append(archive.Queries, *query)
+		}
+		if len(queries.Results) == 0 || page*pageSize >= queries.Count {
+			break
+		}
+	}
+
+	for page := 1; ; page++ {
+		dashboards, err := client.GetDashboards(page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range dashboards.Results {
+			dashboard, err := client.GetDashboard(item.Slug)
+			if err != nil {
+				return nil, err
+			}
+			scrubDashboard(dashboard)
+			archive.Dashboards = append(archive.Dashboards, *dashboard)
+		}
+		if len(dashboards.Results) == 0 || page*pageSize >= dashboards.Count {
+			break
+		}
+	}
+
+	archive.count()
+	return archive, nil
+}
+
+// scrubDashboard clears the share key and the API keys of the queries behind its widgets
+func scrubDashboard(dashboard *redash.Dashboard) {
+	dashboard.APIKey = ""
+	dashboard.PublicUrl = ""
+	for i := range dashboard.Widgets {
+		dashboard.Widgets[i].Visualization.Query.APIKey = ""
+	}
+}
+
+// backupDataSources reads every data source, redacting the options its type marks as secret
+func backupDataSources(client *redash.Client, archive *Archive) error {
+	types, err := client.GetDataSourceTypes()
+	if err != nil {
+		return err
+	}
+	secrets := map[string][]string{}
+	for _, dataSourceType := range types {
+		secrets[dataSourceType.Type] = dataSourceType.ConfigurationSchema.Secret
+	}
+
+	dataSources, err := client.GetDataSources()
+	if err != nil {
+		return err
+	}
+	for _, item := range *dataSources {
+		dataSource, err := client.GetDataSource(item.ID)
+		if err != nil {
+			return err
+		}
+		for _, secret := range secrets[dataSource.Type] {
+			if _, ok := dataSource.Options[secret]; ok {
+				dataSource.Options[secret] = migrate.MaskedSecret
+			}
+		}
+		archive.DataSources = append(archive.DataSources, *dataSource)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/AlmirKadric/redash-client-go/redash/migrate"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackup(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources/types",
+		httpmock.NewStringResponder(200, `[{"type": "pg", "configuration_schema": {"secret": ["password"]}}]`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources",
+		httpmock.NewStringResponder(200, `[{"id": 3, "name": "Warehouse"}]`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources/3",
+		httpmock.NewStringResponder(200, `{"id": 3, "name": "Warehouse", "type": "pg", "options": {"host": "db", "password": "hunter2"}}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/groups",
+		httpmock.NewStringResponder(200, `[{"id": 1, "name": "admin"}, {"id": 2, "name": "default"}]`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/users?page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 1, "results": [{"id": 4, "email": "jane@acme.com"}]}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/users?disabled=true&page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 1, "results": [{"id": 5, "email": "joe@acme.com", "is_disabled": true}]}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries?page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 1, "results": [{"id": 50}]}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/50",
+		httpmock.NewStringResponder(200, `{"id": 50, "name": "Revenue", "api_key": "QuErYkEy", "data_source_id": 3, "visualizations": [{"id": 90, "type": "TABLE"}]}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards?page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 1, "results": [{"id": 7, "slug": "sales"}]}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards/sales",
+		httpmock.NewStringResponder(200, `{"id": 7, "slug": "sales", "api_key": "DaShKeY", "public_url": "https://com.acme/public/dashboards/DaShKeY", "widgets": [{"id": 70, "visualization": {"id": 90, "query": {"id": 50, "api_key": "QuErYkEy"}}}]}`))
+
+	archive, err := Backup(c)
+	assert.Nil(err)
+
+	assert.Equal(ArchiveVersion, archive.Manifest.Version)
+	assert.Equal(map[string]int{"data_sources": 1, "groups": 2, "users": 2, "queries": 1, "dashboards": 1}, archive.Manifest.Counts)
+	assert.Equal(migrate.MaskedSecret, archive.DataSources[0].Options["password"])
+	assert.Equal("db", archive.DataSources[0].Options["host"])
+	assert.True(archive.Users[1].IsDisabled)
+	assert.Equal(90, archive.Queries[0].Visualizations[0].ID)
+	assert.Equal(1, len(archive.Dashboards[0].Widgets))
+
+	dir := filepath.Join(t.TempDir(), "backup")
+	assert.Nil(archive.WriteDir(dir))
+	fromDir, err := ReadDir(dir)
+	assert.Nil(err)
+	assertSameArchive(t, archive, fromDir)
+	for _, file := range archive.files() {
+		info, err := os.Stat(filepath.Join(dir, file.name))
+		assert.Nil(err)
+		assert.Equal(os.FileMode(0600), info.Mode().Perm(), file.name)
+
+		body, _ := ioutil.ReadFile(filepath.Join(dir, file.name))
+		assert.NotContains(string(body), "QuErYkEy", file.name)
+		assert.NotContains(string(body), "DaShKeY", file.name)
+	}
+
+	buffer := bytes.Buffer{}
+	assert.Nil(archive.WriteTar(&buffer))
+	unzipped, err := gzip.NewReader(bytes.NewReader(buffer.Bytes()))
+	assert.Nil(err)
+	tarball, _ := ioutil.ReadAll(unzipped)
+	assert.NotContains(string(tarball), "QuErYkEy")
+	assert.NotContains(string(tarball), "DaShKeY")
+	fromTar, err := ReadTar(&buffer)
+	assert.Nil(err)
+	assertSameArchive(t, archive, fromTar)
+}
+
+func assertSameArchive(t *testing.T, expected, actual *Archive) {
+	for i, file := range expected.files() {
+		expectedJSON, _ := json.Marshal(file.value)
+		actualJSON, _ := json.Marshal(actual.files()[i].value)
+		assert.JSONEq(t, string(expectedJSON), string(actualJSON), file.name)
+	}
+}
+
+func TestReadRejectsUnknownVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	archive := &Archive{Manifest: Manifest{Version: ArchiveVersion + 1}}
+	assert.Nil(archive.WriteDir(dir))
+
+	_, err := ReadDir(dir)
+	assert.EqualError(err, "unsupported archive version 2")
+}
+
+func TestRestore(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	target, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	archive := &Archive{
+		Manifest:    Manifest{Version: ArchiveVersion},
+		DataSources: []redash.DataSource{{ID: 3, Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"host": "db", "password": migrate.MaskedSecret}}},
+		Groups:      []redash.Group{{ID: 1, Name: "admin"}, {ID: 6, Name: "analysts"}},
+		Users: []redash.UserListItem{
+			{ID: 4, Name: "Jane", Email: "jane@acme.com", Groups: []redash.UserListGroup{{ID: 6, Name: "analysts"}}},
+			{ID: 5, Name: "Joe", Email: "joe@acme.com", IsDisabled: true},
+		},
+		Queries: []redash.Query{{ID: 50, Name: "Revenue", DataSourceID: 3, Visualizations: []redash.VisualizationQuery{{ID: 90, Name: "Table", Type: "TABLE"}}}},
+		Dashboards: []redash.Dashboard{{ID: 7, Name: "Sales", Slug: "sales", Widgets: []redash.WidgetDashboard{
+			{ID: 70, Width: 1, Visualization: redash.VisualizationDashboard{ID: 90, Query: redash.QueryDashboard{ID: 50}}},
+		}}},
+	}
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/groups",
+		httpmock.NewStringResponder(200, `[{"id": 11, "name": "admin"}]`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources",
+		httpmock.NewStringResponder(200, `[]`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources/types",
+		httpmock.NewStringResponder(200, `[]`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/users?page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 0, "results": []}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/users?disabled=true&page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 0, "results": []}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/500",
+		httpmock.NewStringResponder(200, `{"id": 500, "visualizations": [{"id": 900, "name": "Table", "type": "TABLE"}]}`))
+
+	created := map[string][]map[string]interface{}{}
+	respond := func(key, response string) httpmock.Responder {
+		return func(req *http.Request) (*http.Response, error) {
+			body := map[string]interface{}{}
+			data, _ := ioutil.ReadAll(req.Body)
+			_ = json.Unmarshal(data, &body)
+			created[key] = append(created[key], body)
+			return httpmock.NewStringResponse(200, response), nil
+		}
+	}
+	httpmock.RegisterResponder("POST", "https://com.acme/api/groups", respond("groups", `{"id": 16, "name": "analysts"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/data_sources", respond("data_sources", `{"id": 30, "name": "Warehouse"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/users", func(req *http.Request) (*http.Response, error) {
+		return respond("users", fmt.Sprintf(`{"id": %d}`, 40+len(created["users"])))(req)
+	})
+	httpmock.RegisterResponder("POST", "https://com.acme/api/users/41/disable", respond("disabled", `{}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/groups/16/members", respond("members", `{}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries", respond("queries", `{"id": 500}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/visualizations/900", respond("visualizations", `{"id": 900}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards", respond("dashboards", `{"id": 700, "slug": "sales"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards/700", respond("dashboards", `{"id": 700, "slug": "sales"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/widgets", respond("widgets", `{"id": 7000}`))
+
+	mapping := migrate.NewMapping()
+	result, err := Restore(archive, target, mapping, migrate.Options{})
+	assert.Nil(err)
+
+	assert.Equal([]string{`data source "Warehouse" is created without its secret option password`}, result.Warnings)
+	assert.Equal(map[string]interface{}{"host": "db"}, created["data_sources"][0]["options"])
+	// Joe is restored and disabled again
+	assert.Equal(2, len(created["users"]))
+	assert.Equal("jane@acme.com", created["users"][0]["email"])
+	assert.Equal("joe@acme.com", created["users"][1]["email"])
+	assert.Equal(1, len(created["disabled"]))
+	assert.Equal(float64(40), created["members"][0]["user_id"])
+	assert.Equal(float64(30), created["queries"][0]["data_source_id"])
+	assert.Equal(float64(900), created["widgets"][0]["visualization_id"])
+
+	assert.Equal(map[int]int{1: 11, 6: 16}, mapping.Groups)
+	assert.Equal(map[int]int{4: 40, 5: 41}, mapping.Users)
+	assert.Equal(map[int]int{50: 500}, mapping.Queries)
+	assert.Equal(map[int]int{7: 700}, mapping.Dashboards)
+}
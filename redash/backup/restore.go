@@ -0,0 +1,92 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package backup
+
+import (
+	"fmt"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/AlmirKadric/redash-client-go/redash/migrate"
+)
+
+// Restore copies the whole archive into target, preferably an empty instance. Objects are
+// matched and remapped as described in package migrate; options.Selection is ignored.
+// Secrets are taken from options.DataSourceOptions, disabled users are created then disabled.
+func Restore(archive *Archive, target *redash.Client, mapping *migrate.Mapping, options migrate.Options) (*migrate.Result, error) {
+	options.Selection = migrate.Selection{DataSources: true, Groups: true, Users: true, DisabledUsers: true}
+	for _, query := range archive.Queries {
+		options.Selection.Queries = append(options.Selection.Queries, query.ID)
+	}
+	for _, dashboard := range archive.Dashboards {
+		options.Selection.Dashboards = append(options.Selection.Dashboards, dashboard.Slug)
+	}
+
+	return migrate.NewMigrator(archive, target, mapping, options).Run()
+}
+
+// The methods below serve the archive as a migrate.Source
+
+// GetDataSources returns the data sources of the archive
+func (a *Archive) GetDataSources() (*[]redash.DataSource, error) {
+	return &a.DataSources, nil
+}
+
+// GetDataSource returns a data source of the archive
+func (a *Archive) GetDataSource(id int) (*redash.DataSource, error) {
+	for i := range a.DataSources {
+		if a.DataSources[i].ID == id {
+			return &a.DataSources[i], nil
+		}
+	}
+	return nil, fmt.Errorf("data source %d is not in the archive", id)
+}
+
+// GetGroups returns the groups of the archive
+func (a *Archive) GetGroups() (*[]redash.Group, error) {
+	return &a.Groups, nil
+}
+
+// EachUser calls fn for every user of the archive, only options.Disabled is applied
+func (a *Archive) EachUser(options *redash.UserListOptions, fn func(user *redash.UserListItem) error) error {
+	disabled := options.Disabled != nil && *options.Disabled
+	for i := range a.Users {
+		if a.Users[i].IsDisabled != disabled {
+			continue
+		}
+		if err := fn(&a.Users[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetQuery returns a query of the archive
+func (a *Archive) GetQuery(id int) (*redash.Query, error) {
+	for i := range a.Queries {
+		if a.Queries[i].ID == id {
+			return &a.Queries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("query %d is not in the archive", id)
+}
+
+// GetDashboard returns a dashboard of the archive
+func (a *Archive) GetDashboard(slug string) (*redash.Dashboard, error) {
+	for i := range a.Dashboards {
+		if a.Dashboards[i].Slug == slug {
+			return &a.Dashboards[i], nil
+		}
+	}
+	return nil, fmt.Errorf("dashboard %s is not in the archive", slug)
+}
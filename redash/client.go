@@ -15,25 +15,66 @@
 package redash
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
-
-	log "github.com/sirupsen/logrus"
+	"time"
 )
 
 // Client contains an active Redash API client
 type Client struct {
 	Config *Config
+
+	ctx context.Context
 }
 
+// Authentication modes
+const (
+	// AuthUserAPIKey sends the API key of a user in the Authorization header, the default
+	AuthUserAPIKey = "user"
+	// AuthQueryAPIKey sends the API key of a query as ?api_key=, only the results of that query can be read
+	AuthQueryAPIKey = "query"
+)
+
+// queryAPIKeyPaths are the read-only result endpoints available with a query API key
+var queryAPIKeyPaths = regexp.MustCompile(`^/api/(queries/\d+/results(/\d+)?(\.json)?|query_results/\d+)$`)
+
 // Config holds the necessary setup vars
 type Config struct {
 	RedashURI  string
 	APIKey     string
 	StrictMode bool
+
+	// AuthMode is AuthUserAPIKey when empty
+	AuthMode string
+
+	// Credentials supplies the API key in place of APIKey when set
+	Credentials CredentialProvider
+
+	// Logger receives the log entries of the client, nothing is logged when it is nil
+	Logger Logger
+
+	// Instrumentation observes every request, such as for tracing and metrics
+	Instrumentation Instrumentation
+
+	// DryRun captures every mutating request into ChangeLog instead of sending it, reads still
+	// reach the server. NewClient creates the ChangeLog when it is nil.
+	DryRun    bool
+	ChangeLog *ChangeLog
+
+	// Timeout limits the duration of every request, zero means no limit
+	Timeout time.Duration
+
+	// Transport sends the requests in place of http.DefaultTransport, such as a recorder.Recorder
+	Transport http.RoundTripper
+
+	// OrgSlug selects an organization of a multi-org instance, requests then go to /<OrgSlug>/api/...
+	OrgSlug string
 }
 
 // NewClient returns a *Client from a valid *Config
@@ -47,10 +88,22 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("Only HTTP(S) URIs allowed")
 	}
 
-	if config.APIKey == "" {
+	if config.APIKey == "" && config.Credentials == nil {
 		return nil, fmt.Errorf("Missing APIKey")
 	}
 
+	if config.AuthMode != "" && config.AuthMode != AuthUserAPIKey && config.AuthMode != AuthQueryAPIKey {
+		return nil, fmt.Errorf("Unsupported AuthMode: %s", config.AuthMode)
+	}
+
+	if err := validateOrgSlug(config.OrgSlug); err != nil {
+		return nil, err
+	}
+
+	if config.DryRun && config.ChangeLog == nil {
+		config.ChangeLog = &ChangeLog{}
+	}
+
 	c := &Client{Config: config}
 	return c, nil
 }
@@ -62,27 +115,118 @@ func (c *Client) IsStrict() bool {
 	return c.Config.StrictMode
 }
 
-func (c *Client) doRequest(method, path, body string, query url.Values) (*http.Response, error) {
-	requestURI := strings.TrimSuffix(c.Config.RedashURI, "/") + path
+// WithOrg returns a copy of the client for another organization of the same instance.
+// Every request of the copy fails if slug is not a valid organization slug.
+func (c *Client) WithOrg(slug string) *Client {
+	config := *c.Config
+	config.OrgSlug = slug
 
-	log.Debug(fmt.Sprintf("[DEBUG] %s request to %s", method, path))
+	clone := *c
+	clone.Config = &config
+	return &clone
+}
 
-	response, err := func() (*http.Response, error) {
-		request, err := http.NewRequest(method, requestURI, strings.NewReader(body))
-		if err != nil {
-			return nil, err
+// WithContext returns a copy of the client whose requests use ctx, for cancellation and tracing
+func (c *Client) WithContext(ctx context.Context) *Client {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+func (c *Client) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
+// ForQuery returns a client reading the results of a query with the query's own API key
+func (c *Client) ForQuery(query *Query) (*Client, error) {
+	if query.APIKey == "" {
+		return nil, fmt.Errorf("query %d has no API key", query.ID)
+	}
+
+	config := *c.Config
+	config.APIKey = query.APIKey
+	config.Credentials = nil
+	config.AuthMode = AuthQueryAPIKey
+
+	clone := *c
+	clone.Config = &config
+	return &clone, nil
+}
+
+// validateOrgSlug rejects slugs that would change the request path instead of selecting an organization
+func validateOrgSlug(slug string) error {
+	if slug == "." || slug == ".." || strings.ContainsAny(slug, "/\\?#% ") {
+		return fmt.Errorf("Invalid OrgSlug %q", slug)
+	}
+	return nil
+}
+
+// EachOrg calls fn with a client for each organization, stopping at the first error.
+// Slugs are all validated before fn is first called.
+func (c *Client) EachOrg(slugs []string, fn func(slug string, client *Client) error) error {
+	for _, slug := range slugs {
+		if err := validateOrgSlug(slug); err != nil {
+			return err
 		}
+	}
 
-		request.Header.Add("Content-Type", "application/json")
-		request.Header.Set("Authorization", "Key "+c.Config.APIKey)
-		request.URL.RawQuery = query.Encode()
+	for _, slug := range slugs {
+		if err := fn(slug, c.WithOrg(slug)); err != nil {
+			return fmt.Errorf("org %s: %s", slug, err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.Config.Timeout > 0 || c.Config.Transport != nil {
+		return &http.Client{Timeout: c.Config.Timeout, Transport: c.Config.Transport}
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) doRequest(method, path, body string, query url.Values) (*http.Response, error) {
+	if c.Config.AuthMode == AuthQueryAPIKey && (method != http.MethodGet || !queryAPIKeyPaths.MatchString(path)) {
+		return nil, fmt.Errorf("%s %s is not available with a query API key", method, path)
+	}
+
+	if err := validateOrgSlug(c.Config.OrgSlug); err != nil {
+		return nil, err
+	}
 
-		return http.DefaultClient.Do(request)
-	}()
+	requestPath := path
+	if c.Config.OrgSlug != "" {
+		requestPath = "/" + c.Config.OrgSlug + path
+	}
+	if c.Config.DryRun && method != http.MethodGet {
+		return c.dryRun(method, path, requestPath, body, query), nil
+	}
+	requestURI := strings.TrimSuffix(c.Config.RedashURI, "/") + requestPath
+
+	key, err := c.credentials().APIKey()
+	if err != nil {
+		return nil, fmt.Errorf("API key: %s", err)
+	}
+
+	response, err := c.send(method, path, requestURI, body, query, key, 1)
 	if err != nil {
 		return nil, err
 	}
 
+	// A rotated key is picked up once, the request is not retried if the key did not change
+	if response.StatusCode == http.StatusUnauthorized && c.Config.Credentials != nil {
+		if refreshed, ok := c.refreshCredentials(key); ok {
+			response.Body.Close()
+			response, err = c.send(method, path, requestURI, body, query, refreshed, 2)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if response.StatusCode < 200 || response.StatusCode > 299 {
 		var body string
 		defer response.Body.Close()
@@ -95,6 +239,116 @@ func (c *Client) doRequest(method, path, body string, query url.Values) (*http.R
 	return response, nil
 }
 
+func (c *Client) send(method, path, requestURI, body string, query url.Values, key string, attempt int) (*http.Response, error) {
+	ctx := c.context()
+	var end func(status int, err error)
+	if c.Config.Instrumentation != nil {
+		ctx, end = c.Config.Instrumentation.StartRequest(ctx, newRequestInfo(method, path, c.Config.OrgSlug, attempt))
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, requestURI, strings.NewReader(body))
+	if err != nil {
+		if end != nil {
+			end(0, err)
+		}
+		return nil, err
+	}
+
+	request.Header.Add("Content-Type", "application/json")
+	if c.Config.AuthMode == AuthQueryAPIKey {
+		query = cloneValues(query)
+		query.Set("api_key", key)
+	} else {
+		request.Header.Set("Authorization", "Key "+key)
+	}
+	request.URL.RawQuery = query.Encode()
+
+	fields := map[string]interface{}{"method": method, "path": path, "attempt": attempt}
+	if body != "" && c.logger().Enabled(LogLevelTrace) {
+		c.log(LogLevelTrace, "Redash request body", map[string]interface{}{"method": method, "path": path, "attempt": attempt, "body": RedactJSON(body)})
+	}
+
+	start := time.Now()
+	response, err := c.httpClient().Do(request)
+	err = scrubURLError(err)
+	fields["duration"] = time.Since(start)
+	if end != nil {
+		if err != nil {
+			end(0, err)
+		} else {
+			end(response.StatusCode, nil)
+		}
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		c.log(LogLevelDebug, "Redash request failed", fields)
+		return nil, err
+	}
+
+	fields["status"] = response.StatusCode
+	c.log(LogLevelDebug, "Redash request", fields)
+
+	if c.logger().Enabled(LogLevelTrace) {
+		b, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		response.Body = io.NopCloser(bytes.NewReader(b))
+		c.log(LogLevelTrace, "Redash response body", map[string]interface{}{"method": method, "path": path, "attempt": attempt, "status": response.StatusCode, "body": RedactJSON(string(b))})
+	}
+
+	return response, nil
+}
+
+// scrubURLError removes the api_key of a query API key from the URL of a request error,
+// so it is not returned, logged or recorded on spans
+func scrubURLError(err error) error {
+	urlError, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	parsed, parseErr := url.Parse(urlError.URL)
+	if parseErr != nil {
+		return &url.Error{Op: urlError.Op, URL: "", Err: urlError.Err}
+	}
+	query := parsed.Query()
+	if query.Get("api_key") == "" {
+		return err
+	}
+	query.Del("api_key")
+	parsed.RawQuery = query.Encode()
+	return &url.Error{Op: urlError.Op, URL: parsed.String(), Err: urlError.Err}
+}
+
+func (c *Client) credentials() CredentialProvider {
+	if c.Config.Credentials != nil {
+		return c.Config.Credentials
+	}
+	return StaticCredentials(c.Config.APIKey)
+}
+
+// refreshCredentials returns the new key after a refresh, and false if it failed or left the key unchanged
+func (c *Client) refreshCredentials(key string) (string, bool) {
+	if err := c.Config.Credentials.Refresh(); err != nil {
+		c.log(LogLevelWarn, "Refreshing credentials failed", map[string]interface{}{"error": err.Error()})
+		return "", false
+	}
+	refreshed, err := c.Config.Credentials.APIKey()
+	if err != nil || refreshed == key {
+		return "", false
+	}
+	return refreshed, true
+}
+
+func cloneValues(values url.Values) url.Values {
+	clone := url.Values{}
+	for key, value := range values {
+		clone[key] = append([]string{}, value...)
+	}
+	return clone
+}
+
 func (c *Client) get(path string, query url.Values) (*http.Response, error) {
 	return c.doRequest(http.MethodGet, path, "", query)
 }
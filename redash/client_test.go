@@ -14,8 +14,13 @@
 package redash
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,4 +50,136 @@ func TestNewClient(t *testing.T) {
 	c, err = NewClient(&Config{RedashURI: "http://valid.url", APIKey: "RanD0mStr1nG"})
 	assert.Nil(err)
 	assert.NotNil(c)
+
+	c, err = NewClient(&Config{RedashURI: "https://valid.url/", APIKey: "RanD0mStr1nG", OrgSlug: "acme/eu"})
+	assert.NotNil(err)
+	assert.Nil(c)
+}
+
+func TestOrgSlug(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://com.acme/acme-eu/api/queries/1",
+		httpmock.NewStringResponder(200, `{"id": 1, "name": "EU revenue"}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/acme-us/api/queries/1",
+		httpmock.NewStringResponder(200, `{"id": 1, "name": "US revenue"}`))
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy", OrgSlug: "acme-eu"})
+
+	query, err := c.GetQuery(1)
+	assert.Nil(err)
+	assert.Equal("EU revenue", query.Name)
+
+	// WithOrg leaves the original client alone
+	query, err = c.WithOrg("acme-us").GetQuery(1)
+	assert.Nil(err)
+	assert.Equal("US revenue", query.Name)
+	assert.Equal("acme-eu", c.Config.OrgSlug)
+
+	names := []string{}
+	err = c.EachOrg([]string{"acme-us", "acme-eu", "acme-apac"}, func(slug string, client *Client) error {
+		query, err := client.GetQuery(1)
+		if err != nil {
+			return err
+		}
+		names = append(names, query.Name)
+		return nil
+	})
+	assert.Equal([]string{"US revenue", "EU revenue"}, names)
+	assert.Contains(err.Error(), "org acme-apac: ")
+
+	// Slugs that would change the request path are rejected before anything is sent
+	for _, slug := range []string{"acme/../other", "..", "acme?x=1", "acme#", "acme%2F"} {
+		_, err = c.WithOrg(slug).GetQuery(1)
+		assert.EqualError(err, fmt.Sprintf("Invalid OrgSlug %q", slug))
+	}
+	_, err = NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy", OrgSlug: ".."})
+	assert.EqualError(err, `Invalid OrgSlug ".."`)
+
+	calls := 0
+	err = c.EachOrg([]string{"acme-us", "acme/eu"}, func(slug string, client *Client) error {
+		calls++
+		return nil
+	})
+	assert.EqualError(err, `Invalid OrgSlug "acme/eu"`)
+	assert.Equal(0, calls)
+}
+
+func TestClientTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewClient(&Config{RedashURI: "https://valid.url/", APIKey: "RanD0mStr1nG"})
+	assert.Equal(http.DefaultClient, c.httpClient())
+
+	c, _ = NewClient(&Config{RedashURI: "https://valid.url/", APIKey: "RanD0mStr1nG", Timeout: 10 * time.Second})
+	assert.Equal(10*time.Second, c.httpClient().Timeout)
+
+	c, _ = NewClient(&Config{RedashURI: "https://valid.url/", APIKey: "RanD0mStr1nG", Transport: httpmock.DefaultTransport})
+	assert.Equal(httpmock.DefaultTransport, c.httpClient().Transport)
+}
+
+func TestQueryAPIKeyAuth(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/1/results.json",
+		func(req *http.Request) (*http.Response, error) {
+			if req.URL.Query().Get("api_key") != "QuErYkEy" || req.Header.Get("Authorization") != "" {
+				return httpmock.NewStringResponse(403, `{"message": "forbidden"}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"query_result": {"id": 3919563, "data_source_id": 2}}`), nil
+		})
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	_, err := c.ForQuery(&Query{ID: 1})
+	assert.NotNil(err)
+
+	qc, err := c.ForQuery(&Query{ID: 1, APIKey: "QuErYkEy"})
+	assert.Nil(err)
+	assert.Equal("", c.Config.AuthMode)
+	assert.Equal(AuthQueryAPIKey, qc.Config.AuthMode)
+
+	result, err := qc.GetQueryResults(1)
+	assert.Nil(err)
+	assert.Equal(3919563, result.ID)
+
+	_, err = qc.GetQuery(1)
+	assert.EqualError(err, "GET /api/queries/1 is not available with a query API key")
+
+	_, err = qc.ExecuteQuery(1, &QueryExecutePayload{})
+	assert.EqualError(err, "POST /api/queries/1/results is not available with a query API key")
+
+	_, err = NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "QuErYkEy", AuthMode: "cookie"})
+	assert.NotNil(err)
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestQueryAPIKeyTransportError(t *testing.T) {
+	assert := assert.New(t)
+
+	logger := &recordingLogger{level: LogLevelDebug}
+	instrumentation := &recordingInstrumentation{}
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "QuErYkEy", AuthMode: AuthQueryAPIKey,
+		Transport: failingTransport{}, Logger: logger, Instrumentation: instrumentation})
+
+	_, err := c.GetQueryResults(1)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "https://com.acme/api/queries/1/results.json")
+	assert.Contains(err.Error(), "connection refused")
+	assert.NotContains(err.Error(), "QuErYkEy")
+
+	assert.Len(logger.entries, 1)
+	assert.NotContains(logger.entries[0].fields["error"], "QuErYkEy")
+
+	assert.Len(instrumentation.requests, 1)
+	assert.NotContains(instrumentation.requests[0].err.Error(), "QuErYkEy")
 }
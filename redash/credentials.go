@@ -0,0 +1,166 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialProvider supplies the API key of every request. Refresh is called after a
+// 401 response, the request is then retried once if the key changed.
+type CredentialProvider interface {
+	APIKey() (string, error)
+	Refresh() error
+}
+
+type staticCredentials string
+
+// StaticCredentials returns a provider of a fixed key, the default for Config.APIKey
+func StaticCredentials(key string) CredentialProvider {
+	return staticCredentials(key)
+}
+
+func (s staticCredentials) APIKey() (string, error) {
+	return string(s), nil
+}
+
+func (s staticCredentials) Refresh() error {
+	return nil
+}
+
+type envCredentials string
+
+// EnvCredentials returns a provider reading the key from an environment variable on every request
+func EnvCredentials(name string) CredentialProvider {
+	return envCredentials(name)
+}
+
+func (e envCredentials) APIKey() (string, error) {
+	key := os.Getenv(string(e))
+	if key == "" {
+		return "", fmt.Errorf("environment variable %s is not set", string(e))
+	}
+	return key, nil
+}
+
+func (e envCredentials) Refresh() error {
+	return nil
+}
+
+// FileCredentials reads the key from a file, the file is read again when it changes
+type FileCredentials struct {
+	Path string
+
+	mutex   sync.Mutex
+	key     string
+	modTime time.Time
+	size    int64
+}
+
+// NewFileCredentials returns a provider reading the key from a file
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{Path: path}
+}
+
+// APIKey returns the key, reading the file again if its modification time or size changed
+func (f *FileCredentials) APIKey() (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return "", err
+	}
+	if f.key != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.key, nil
+	}
+	return f.read(info)
+}
+
+// Refresh reads the file again
+func (f *FileCredentials) Refresh() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return err
+	}
+	_, err = f.read(info)
+	return err
+}
+
+func (f *FileCredentials) read(info os.FileInfo) (string, error) {
+	body, err := ioutil.ReadFile(f.Path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(body))
+	if key == "" {
+		return "", fmt.Errorf("%s: empty API key", f.Path)
+	}
+	f.key, f.modTime, f.size = key, info.ModTime(), info.Size()
+	return key, nil
+}
+
+// CommandCredentials runs a shell command whose output is the key, such as a call to a
+// secrets manager. The key is kept until Refresh runs the command again.
+type CommandCredentials struct {
+	Command string
+
+	mutex sync.Mutex
+	key   string
+}
+
+// NewCommandCredentials returns a provider running command with sh -c
+func NewCommandCredentials(command string) *CommandCredentials {
+	return &CommandCredentials{Command: command}
+}
+
+// APIKey returns the key, running the command the first time
+func (c *CommandCredentials) APIKey() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.key != "" {
+		return c.key, nil
+	}
+	return c.run()
+}
+
+// Refresh runs the command again
+func (c *CommandCredentials) Refresh() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, err := c.run()
+	return err
+}
+
+func (c *CommandCredentials) run() (string, error) {
+	key, err := runKeyCommand(c.Command)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", fmt.Errorf("empty API key")
+	}
+	c.key = key
+	return key, nil
+}
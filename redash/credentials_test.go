@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("REDASH_TEST_KEY", "")
+	_, err := EnvCredentials("REDASH_TEST_KEY").APIKey()
+	assert.EqualError(err, "environment variable REDASH_TEST_KEY is not set")
+
+	t.Setenv("REDASH_TEST_KEY", "EnVkEy")
+	key, err := EnvCredentials("REDASH_TEST_KEY").APIKey()
+	assert.Nil(err)
+	assert.Equal("EnVkEy", key)
+}
+
+func TestFileCredentials(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "api_key")
+
+	credentials := NewFileCredentials(path)
+	_, err := credentials.APIKey()
+	assert.NotNil(err)
+
+	assert.Nil(ioutil.WriteFile(path, []byte("FiRsTkEy\n"), 0600))
+	key, err := credentials.APIKey()
+	assert.Nil(err)
+	assert.Equal("FiRsTkEy", key)
+
+	// A rotated key is read again on change
+	assert.Nil(ioutil.WriteFile(path, []byte("SeCoNdKeYs\n"), 0600))
+	key, err = credentials.APIKey()
+	assert.Nil(err)
+	assert.Equal("SeCoNdKeYs", key)
+}
+
+func TestCommandCredentials(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "api_key")
+	assert.Nil(ioutil.WriteFile(path, []byte("FiRsTkEy"), 0600))
+
+	credentials := NewCommandCredentials("cat " + path)
+	key, err := credentials.APIKey()
+	assert.Nil(err)
+	assert.Equal("FiRsTkEy", key)
+
+	// The key is kept until refreshed
+	assert.Nil(ioutil.WriteFile(path, []byte("SeCoNdKeY"), 0600))
+	key, _ = credentials.APIKey()
+	assert.Equal("FiRsTkEy", key)
+
+	assert.Nil(credentials.Refresh())
+	key, _ = credentials.APIKey()
+	assert.Equal("SeCoNdKeY", key)
+
+	_, err = NewCommandCredentials("true").APIKey()
+	assert.EqualError(err, "empty API key")
+}
+
+func TestCredentialsRefreshOn401(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	path := filepath.Join(t.TempDir(), "api_key")
+	assert.Nil(ioutil.WriteFile(path, []byte("OlDkEy"), 0600))
+
+	keys := []string{}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/1",
+		func(req *http.Request) (*http.Response, error) {
+			keys = append(keys, req.Header.Get("Authorization"))
+			if req.Header.Get("Authorization") != "Key NeWkEy" {
+				return httpmock.NewStringResponse(401, `{"message": "Couldn't find resource. Please login and try again."}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"id": 1, "name": "Revenue"}`), nil
+		})
+
+	c, err := NewClient(&Config{RedashURI: "https://com.acme/", Credentials: NewCommandCredentials("cat " + path)})
+	assert.Nil(err)
+
+	// The key did not change, the 401 is returned without a retry
+	_, err = c.GetQuery(1)
+	assert.NotNil(err)
+	assert.Equal([]string{"Key OlDkEy"}, keys)
+
+	// The key was rotated, the request is retried once with the new key
+	assert.Nil(ioutil.WriteFile(path, []byte("NeWkEy"), 0600))
+	keys = []string{}
+	query, err := c.GetQuery(1)
+	assert.Nil(err)
+	assert.Equal("Revenue", query.Name)
+	assert.Equal([]string{"Key OlDkEy", "Key NeWkEy"}, keys)
+
+	// Static keys are never retried
+	keys = []string{}
+	c, _ = NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "OlDkEy"})
+	_, err = c.GetQuery(1)
+	assert.NotNil(err)
+	assert.Equal([]string{"Key OlDkEy"}, keys)
+}
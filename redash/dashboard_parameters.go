@@ -0,0 +1,158 @@
+package redash
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Types of WidgetParameterMapping
+const (
+	ParameterMappingDashboardLevel = "dashboard-level"
+	ParameterMappingWidgetLevel    = "widget-level"
+	ParameterMappingStaticValue    = "static-value"
+)
+
+// DashboardParameter is a dashboard-level parameter widgets can be wired to
+type DashboardParameter struct {
+	// Name of the dashboard-level parameter, used as MapTo
+	Name string
+	// Title displayed on the dashboard, defaults to the query parameter title
+	Title string
+	// QueryParameter is the query parameter name to wire, defaults to Name
+	QueryParameter string
+	// Type restricts wiring to query parameters of this type (e.g. "date-range"), empty accepts any
+	Type string
+}
+
+// UnmappedParameter is a parameter the wiring could not connect
+type UnmappedParameter struct {
+	// WidgetID is 0 for dashboard parameters no widget could use
+	WidgetID  int
+	Parameter string
+	Reason    string
+}
+
+// ParameterWiring holds the parameter mappings computed for each widget of a dashboard
+type ParameterWiring struct {
+	Mappings map[int]map[string]WidgetParameterMapping
+	Changed  []int
+	Unmapped []UnmappedParameter
+}
+
+// WireDashboardParameters computes the ParameterMappings of every widget of a dashboard.
+// Query parameters matching a dashboard parameter by name (and type, when set) are mapped to it,
+// the others keep their current mapping or become widget-level parameters.
+func WireDashboardParameters(dashboard *Dashboard, parameters []DashboardParameter) *ParameterWiring {
+	wiring := &ParameterWiring{Mappings: map[int]map[string]WidgetParameterMapping{}}
+
+	byQueryParameter := map[string]DashboardParameter{}
+	used := map[string]bool{}
+	for _, parameter := range parameters {
+		if parameter.QueryParameter == "" {
+			parameter.QueryParameter = parameter.Name
+		}
+		byQueryParameter[parameter.QueryParameter] = parameter
+	}
+
+	for _, widget := range dashboard.Widgets {
+		if widget.IsText() {
+			continue
+		}
+
+		mappings := map[string]WidgetParameterMapping{}
+		for _, queryParameter := range widget.Visualization.Query.Options.Parameters {
+			current, hasCurrent := widget.Options.ParameterMappings[queryParameter.Name]
+
+			reason := "no matching dashboard parameter"
+			parameter, matched := byQueryParameter[queryParameter.Name]
+			if matched && parameter.Type != "" && parameter.Type != queryParameter.Type {
+				reason = fmt.Sprintf("query parameter type %s does not match dashboard parameter %s of type %s", queryParameter.Type, parameter.Name, parameter.Type)
+				matched = false
+			}
+
+			switch {
+			case matched:
+				title := parameter.Title
+				if title == "" {
+					title = queryParameter.Title
+				}
+				mappings[queryParameter.Name] = WidgetParameterMapping{
+					Name:  queryParameter.Name,
+					Type:  ParameterMappingDashboardLevel,
+					MapTo: parameter.Name,
+					Title: title,
+				}
+				used[parameter.QueryParameter] = true
+				continue
+			case hasCurrent:
+				mappings[queryParameter.Name] = current
+			default:
+				mappings[queryParameter.Name] = WidgetParameterMapping{
+					Name:  queryParameter.Name,
+					Type:  ParameterMappingWidgetLevel,
+					MapTo: queryParameter.Name,
+				}
+			}
+
+			if mappings[queryParameter.Name].Type != ParameterMappingDashboardLevel {
+				wiring.Unmapped = append(wiring.Unmapped, UnmappedParameter{
+					WidgetID:  widget.ID,
+					Parameter: queryParameter.Name,
+					Reason:    reason,
+				})
+			}
+		}
+
+		wiring.Mappings[widget.ID] = mappings
+		if len(mappings)+len(widget.Options.ParameterMappings) > 0 && !reflect.DeepEqual(mappings, widget.Options.ParameterMappings) {
+			wiring.Changed = append(wiring.Changed, widget.ID)
+		}
+	}
+
+	names := []string{}
+	for name := range byQueryParameter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !used[name] {
+			wiring.Unmapped = append(wiring.Unmapped, UnmappedParameter{
+				Parameter: byQueryParameter[name].Name,
+				Reason:    "no widget has a query parameter named " + name,
+			})
+		}
+	}
+
+	return wiring
+}
+
+// ApplyParameterWiring saves the computed ParameterMappings onto the widgets that changed
+func (c *Client) ApplyParameterWiring(dashboard *Dashboard, wiring *ParameterWiring) error {
+	changed := map[int]bool{}
+	for _, id := range wiring.Changed {
+		changed[id] = true
+	}
+
+	for i, widget := range dashboard.Widgets {
+		if !changed[widget.ID] {
+			continue
+		}
+
+		options := widget.Options
+		options.ParameterMappings = wiring.Mappings[widget.ID]
+		visualizationId := widget.Visualization.ID
+		updated, err := c.UpdateWidget(widget.ID, &WidgetUpdatePayload{
+			Text:            widget.Text,
+			Width:           widget.Width,
+			VisualizationID: &visualizationId,
+			Options:         options,
+		})
+		if err != nil {
+			return err
+		}
+		dashboard.Widgets[i].Options = updated.Options
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package redash
+
+import (
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func parameterDashboard() *Dashboard {
+	widget := func(id int, parameters ...QueryOptionsParameter) WidgetDashboard {
+		w := WidgetDashboard{ID: id, Visualization: VisualizationDashboard{ID: id * 10}}
+		w.Visualization.Query.Options.Parameters = parameters
+		return w
+	}
+
+	dashboard := &Dashboard{Widgets: []WidgetDashboard{
+		widget(1, QueryOptionsParameter{Name: "service_name", Title: "Service", Type: "text"}, QueryOptionsParameter{Name: "day_range", Type: "date-range"}),
+		widget(2, QueryOptionsParameter{Name: "service_name", Type: "text"}, QueryOptionsParameter{Name: "limit", Type: "number"}),
+		widget(3, QueryOptionsParameter{Name: "day_range", Type: "number"}),
+		{ID: 4, Text: "## Notes"},
+	}}
+	dashboard.Widgets[1].Options.ParameterMappings = map[string]WidgetParameterMapping{
+		"limit": {Name: "limit", Type: ParameterMappingStaticValue, MapTo: "limit", Value: "10"},
+	}
+
+	return dashboard
+}
+
+func TestWireDashboardParameters(t *testing.T) {
+	assert := assert.New(t)
+
+	dashboard := parameterDashboard()
+	wiring := WireDashboardParameters(dashboard, []DashboardParameter{
+		{Name: "service", QueryParameter: "service_name"},
+		{Name: "day_range", Type: "date-range", Title: "Days"},
+		{Name: "region"},
+	})
+
+	assert.Equal([]int{1, 2, 3}, wiring.Changed)
+	assert.Equal(WidgetParameterMapping{Name: "service_name", Type: ParameterMappingDashboardLevel, MapTo: "service", Title: "Service"}, wiring.Mappings[1]["service_name"])
+	assert.Equal(WidgetParameterMapping{Name: "day_range", Type: ParameterMappingDashboardLevel, MapTo: "day_range", Title: "Days"}, wiring.Mappings[1]["day_range"])
+	assert.Equal(ParameterMappingStaticValue, wiring.Mappings[2]["limit"].Type)
+	assert.Equal(ParameterMappingWidgetLevel, wiring.Mappings[3]["day_range"].Type)
+	assert.NotContains(wiring.Mappings, 4)
+
+	assert.Equal(3, len(wiring.Unmapped))
+	assert.Equal(UnmappedParameter{WidgetID: 2, Parameter: "limit", Reason: "no matching dashboard parameter"}, wiring.Unmapped[0])
+	assert.Equal(3, wiring.Unmapped[1].WidgetID)
+	assert.Contains(wiring.Unmapped[1].Reason, "does not match")
+	assert.Equal(UnmappedParameter{Parameter: "region", Reason: "no widget has a query parameter named region"}, wiring.Unmapped[2])
+}
+
+func TestApplyParameterWiring(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/widgets/1",
+		httpmock.NewStringResponder(200, `{"id": 1, "options": {"parameterMappings": {"service_name": {"name": "service_name", "type": "dashboard-level", "mapTo": "service"}}}}`))
+
+	dashboard := parameterDashboard()
+	dashboard.Widgets = dashboard.Widgets[:1]
+	wiring := WireDashboardParameters(dashboard, []DashboardParameter{{Name: "service", QueryParameter: "service_name"}})
+
+	err := c.ApplyParameterWiring(dashboard, wiring)
+	assert.Nil(err)
+	assert.Equal(1, httpmock.GetTotalCallCount())
+	assert.Equal("service", dashboard.Widgets[0].Options.ParameterMappings["service_name"].MapTo)
+}
@@ -120,6 +120,40 @@ type DashboardUpdatePayload struct {
 	Tags []string `json:"tags"`
 }
 
+// DashboardShare object structure from Redash's /api/dashboards/<ID>/share endpoint
+type DashboardShare struct {
+	PublicURL string `json:"public_url"`
+	APIKey    string `json:"api_key"`
+}
+
+// DashboardShareReport lists dashboards that are publicly shared
+type DashboardShareReport struct {
+	Checked int
+	Shared  []Dashboard
+}
+
+// GetDashboards returns a paginated list of dashboards
+func (c *Client) GetDashboards(page, pageSize int) (*DashboardList, error) {
+	path := "/api/dashboards"
+
+	queryParams := url.Values{}
+	queryParams.Add("page", strconv.Itoa(page))
+	queryParams.Add("page_size", strconv.Itoa(pageSize))
+	response, err := c.get(path, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	dashboards := new(DashboardList)
+	err = json.NewDecoder(response.Body).Decode(dashboards)
+	if err != nil {
+		return nil, err
+	}
+
+	return dashboards, nil
+}
+
 // GetDashboard gets a specific dashboard by its slug
 func (c *Client) GetDashboard(slug string) (*Dashboard, error) {
 	path := "/api/dashboards/" + slug
@@ -198,3 +232,63 @@ func (c *Client) ArchiveDashboard(slug string) error {
 
 	return err
 }
+
+// ShareDashboard enables public access to a dashboard and returns its public URL and API key
+func (c *Client) ShareDashboard(id int) (*DashboardShare, error) {
+	path := "/api/dashboards/" + strconv.Itoa(id) + "/share"
+
+	queryParams := url.Values{}
+	response, err := c.post(path, "", queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	share := new(DashboardShare)
+	err = json.NewDecoder(response.Body).Decode(share)
+	if err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// UnshareDashboard revokes public access to a dashboard
+func (c *Client) UnshareDashboard(id int) error {
+	path := "/api/dashboards/" + strconv.Itoa(id) + "/share"
+
+	_, err := c.delete(path, url.Values{})
+
+	return err
+}
+
+// AuditSharedDashboards walks every dashboard and reports the ones that are publicly shared
+func (c *Client) AuditSharedDashboards() (*DashboardShareReport, error) {
+	report := &DashboardShareReport{}
+
+	pageSize := 100
+	for page := 1; ; page++ {
+		dashboards, err := c.GetDashboards(page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range dashboards.Results {
+			dashboard, err := c.GetDashboard(item.Slug)
+			if err != nil {
+				return nil, err
+			}
+
+			report.Checked++
+			if dashboard.PublicUrl != "" {
+				report.Shared = append(report.Shared, *dashboard)
+			}
+		}
+
+		if len(dashboards.Results) == 0 || page*pageSize >= dashboards.Count {
+			break
+		}
+	}
+
+	return report, nil
+}
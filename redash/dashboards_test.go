@@ -115,3 +115,74 @@ func TestArchiveDashboard(t *testing.T) {
 	err := c.ArchiveDashboard("my-dashboard")
 	assert.Nil(err)
 }
+
+func TestGetDashboards(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards?page=1&page_size=25",
+		httpmock.NewStringResponder(200, `{"count": 1, "page": 1, "page_size": 25, "results": [{"id": 1, "slug": "service-slos", "name": "Service SLOs"}]}`))
+
+	dashboards, err := c.GetDashboards(1, 25)
+	assert.Nil(err)
+
+	assert.Equal(1, dashboards.Count)
+	assert.Equal(1, len(dashboards.Results))
+	assert.Equal("service-slos", dashboards.Results[0].Slug)
+}
+
+func TestShareDashboard(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards/5/share",
+		httpmock.NewStringResponder(200, `{"public_url": "https://com.acme/public/dashboards/SeCrEt", "api_key": "SeCrEt"}`))
+
+	share, err := c.ShareDashboard(5)
+	assert.Nil(err)
+
+	assert.Equal("https://com.acme/public/dashboards/SeCrEt", share.PublicURL)
+	assert.Equal("SeCrEt", share.APIKey)
+}
+
+func TestUnshareDashboard(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/dashboards/5/share",
+		httpmock.NewStringResponder(200, `{}`))
+
+	err := c.UnshareDashboard(5)
+	assert.Nil(err)
+}
+
+func TestAuditSharedDashboards(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards?page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 2, "page": 1, "page_size": 100, "results": [{"id": 1, "slug": "private"}, {"id": 2, "slug": "shared"}]}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards/private",
+		httpmock.NewStringResponder(200, `{"id": 1, "slug": "private"}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards/shared",
+		httpmock.NewStringResponder(200, `{"id": 2, "slug": "shared", "public_url": "https://com.acme/public/dashboards/SeCrEt", "api_key": "SeCrEt"}`))
+
+	report, err := c.AuditSharedDashboards()
+	assert.Nil(err)
+
+	assert.Equal(2, report.Checked)
+	assert.Equal(1, len(report.Shared))
+	assert.Equal("shared", report.Shared[0].Slug)
+}
@@ -0,0 +1,279 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package dashcode
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/AlmirKadric/redash-client-go/redash/redashtest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	body, err := ioutil.ReadFile("../testdata/get-dashboard.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards/service-slos",
+		httpmock.NewStringResponder(200, string(body)))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources",
+		httpmock.NewStringResponder(200, `[{"id": 18, "name": "Metrics"}]`))
+
+	document, err := Export(c, "service-slos")
+	assert.Nil(err)
+	assert.Nil(document.Validate())
+
+	spec := document.Dashboard
+	assert.Equal("Service SLOs", spec.Name)
+	assert.Equal(4, len(spec.Queries))
+	assert.Equal([]string{"query-name", "query-name-2", "query-name-3", "query-name-4"},
+		[]string{spec.Queries[0].Key, spec.Queries[1].Key, spec.Queries[2].Key, spec.Queries[3].Key})
+	assert.Equal("Metrics", spec.Queries[0].DataSource)
+	assert.Equal("bytes", spec.Queries[0].Visualizations[0].Key)
+
+	// Widgets are sorted by position
+	assert.Equal(4, len(spec.Widgets))
+	assert.Equal(10, spec.Widgets[0].Position.Row)
+	assert.Equal("query-name", spec.Widgets[0].Query)
+	assert.Equal("bytes", spec.Widgets[0].Visualization)
+	assert.Equal(54, spec.Widgets[3].Position.Row)
+
+	// Nothing instance specific is written out
+	encoded, err := document.Encode(FormatJSON)
+	assert.Nil(err)
+	assert.NotContains(string(encoded), "122797")
+	assert.NotContains(string(encoded), "237170")
+	assert.NotContains(string(encoded), `"data_source_id"`)
+
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		encoded, err := document.Encode(format)
+		assert.Nil(err)
+
+		decoded, err := Decode(encoded)
+		assert.Nil(err)
+
+		expected, _ := json.Marshal(document)
+		actual, _ := json.Marshal(decoded)
+		assert.JSONEq(string(expected), string(actual))
+	}
+
+	yaml, _ := document.Encode(FormatYAML)
+	assert.True(strings.HasPrefix(string(yaml), "version: 1\ndashboard:\n  name: Service SLOs\n"))
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Decode([]byte(`{"version": 2, "dashboard": {"name": "x"}}`))
+	assert.NotNil(err)
+
+	_, err = Decode([]byte(`
+version: 1
+dashboard:
+  name: Sales
+  queries:
+    - key: revenue
+      name: Revenue
+      data_source: Warehouse
+      query: SELECT 1
+  widgets:
+    - query: revenue
+      visualization: chart
+`))
+	assert.NotNil(err)
+}
+
+func TestImport(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	requests := []string{}
+	record := func(response string) httpmock.Responder {
+		return func(request *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(request.Body)
+			requests = append(requests, request.Method+" "+request.URL.Path+" "+string(body))
+			return httpmock.NewStringResponse(200, response), nil
+		}
+	}
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources",
+		httpmock.NewStringResponder(200, `[{"id": 3, "name": "Warehouse"}]`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards?page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 0, "results": []}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards",
+		record(`{"id": 9, "slug": "sales", "name": "Sales"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries",
+		record(`{"id": 40}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/40",
+		httpmock.NewStringResponder(200, `{"id": 40, "version": 1, "visualizations": [{"id": 400, "name": "Table", "type": "TABLE"}]}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/visualizations/400",
+		record(`{"id": 400}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/visualizations",
+		record(`{"id": 401}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/widgets",
+		record(`{"id": 1}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards/9",
+		record(`{"id": 9, "slug": "sales", "name": "Sales"}`))
+
+	document, err := Decode([]byte(`
+version: 1
+dashboard:
+  name: Sales
+  slug: sales
+  tags: [finance]
+  queries:
+    - key: revenue
+      name: Revenue
+      data_source: Warehouse
+      query: SELECT day, revenue FROM sales
+      visualizations:
+        - key: table
+          name: Table
+          type: TABLE
+          options: {}
+        - key: chart
+          name: Revenue by day
+          type: CHART
+          options: {globalSeriesType: line}
+  widgets:
+    - text: "## Revenue"
+      position: {col: 0, row: 0, sizeX: 6, sizeY: 2}
+    - query: revenue
+      visualization: chart
+      position: {col: 0, row: 2, sizeX: 6, sizeY: 8}
+`))
+	assert.Nil(err)
+
+	result, err := Import(c, document)
+	assert.Nil(err)
+
+	assert.Equal(9, result.DashboardID)
+	assert.Equal("sales", result.Slug)
+	assert.Equal([]string{"dashboard sales", "query revenue", "visualization revenue/chart", "widget 0", "widget 1"}, result.Created)
+	assert.Equal([]string{"visualization revenue/table", "dashboard sales"}, result.Updated)
+
+	assert.Equal(7, len(requests))
+	assert.Contains(requests[1], `"data_source_id":3`)
+	assert.Contains(requests[4], `"visualization_id":null`)
+	assert.Contains(requests[5], `"visualization_id":401`)
+	assert.Contains(requests[6], `"tags":["finance"]`)
+}
+
+func TestImportTwice(t *testing.T) {
+	assert := assert.New(t)
+
+	server := redashtest.NewServer()
+	defer server.Close()
+	c := server.Client()
+	_, err := c.CreateDataSource(&redash.DataSource{Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"dbname": "dwh"}})
+	assert.Nil(err)
+
+	// A hand-written slug, a document without slug, and a dropdown fed by a query no widget displays
+	documents := []string{`
+version: 1
+dashboard:
+  name: Sales
+  slug: sales-2022
+  queries:
+    - key: revenue
+      name: Revenue
+      data_source: Warehouse
+      query: SELECT day, revenue FROM sales WHERE region = '{{ region }}'
+      options:
+        parameters:
+          - {name: region, title: Region, type: query}
+      dropdowns: {region: regions}
+      visualizations:
+        - {key: table, name: Table, type: TABLE, options: {}}
+    - key: regions
+      name: Regions
+      data_source: Warehouse
+      query: SELECT DISTINCT region FROM sales
+  widgets:
+    - query: revenue
+      visualization: table
+      position: {col: 0, row: 0, sizeX: 6, sizeY: 8}
+`, `
+version: 1
+dashboard:
+  name: Costs
+  widgets:
+    - text: "## Costs"
+      position: {col: 0, row: 0, sizeX: 6, sizeY: 2}
+`}
+
+	for _, body := range documents {
+		document, err := Decode([]byte(body))
+		assert.Nil(err)
+
+		first, err := Import(c, document)
+		assert.Nil(err)
+		second, err := Import(c, document)
+		assert.Nil(err)
+		assert.Equal(first.DashboardID, second.DashboardID)
+		assert.Equal(first.Slug, second.Slug)
+		assert.Empty(second.Created)
+	}
+
+	dashboards, err := c.GetDashboards(1, 25)
+	assert.Nil(err)
+	assert.Equal(2, dashboards.Count)
+	queries, err := c.ListQueries(1, 25)
+	assert.Nil(err)
+	assert.Equal(2, queries.Count)
+
+	// The dropdown points at the imported query, and exports back to its key
+	dashboard, err := c.GetDashboard("sales-2022")
+	assert.Nil(err)
+	revenue := dashboard.Widgets[0].Visualization.Query
+	regions, err := c.GetQuery(revenue.Options.Parameters[0].QueryID)
+	assert.Nil(err)
+	assert.Equal("Regions", regions.Name)
+
+	document, err := Export(c, "sales-2022")
+	assert.Nil(err)
+	assert.Nil(document.Validate())
+	assert.Equal(map[string]string{"region": "regions"}, document.Dashboard.Queries[0].Dropdowns)
+	assert.Equal("Regions", document.Dashboard.Queries[1].Name)
+	assert.Empty(document.Dashboard.Queries[1].Visualizations)
+}
+
+func TestValidateDropdowns(t *testing.T) {
+	assert := assert.New(t)
+
+	document := &Document{Version: DocumentVersion, Dashboard: DashboardSpec{Name: "Sales", Queries: []QuerySpec{{
+		Key: "revenue", Name: "Revenue", DataSource: "Warehouse",
+		Options: redash.QueryOptions{Parameters: []redash.QueryOptionsParameter{{Name: "region", Type: "query", QueryID: 12}}},
+	}}}}
+	assert.EqualError(document.Validate(), "parameter region of query revenue reads its values from query 12, reference that query by key in dropdowns")
+
+	document.Dashboard.Queries[0].Options.Parameters[0].QueryID = 0
+	document.Dashboard.Queries[0].Dropdowns = map[string]string{"region": "regions"}
+	assert.EqualError(document.Validate(), `dropdown region of query revenue references unknown query "regions"`)
+}
@@ -0,0 +1,278 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Package dashcode exports Redash dashboards to portable, ID-free YAML or JSON
+// documents and imports them back, so dashboards can be reviewed in git
+package dashcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"gopkg.in/yaml.v3"
+)
+
+// DocumentVersion is the version of the document format written by Export
+const DocumentVersion = 1
+
+// Document is a portable description of a dashboard and everything it displays
+type Document struct {
+	Version   int           `json:"version"`
+	Dashboard DashboardSpec `json:"dashboard"`
+}
+
+// DashboardSpec describes a dashboard. Queries are referenced by widgets through their Key.
+type DashboardSpec struct {
+	Name                    string       `json:"name"`
+	Slug                    string       `json:"slug,omitempty"`
+	Tags                    []string     `json:"tags,omitempty"`
+	IsDraft                 bool         `json:"is_draft,omitempty"`
+	DashboardFiltersEnabled bool         `json:"dashboard_filters_enabled,omitempty"`
+	Queries                 []QuerySpec  `json:"queries,omitempty"`
+	Widgets                 []WidgetSpec `json:"widgets,omitempty"`
+}
+
+// QuerySpec describes a query, its data source is referenced by name. Dropdowns maps the
+// name of each query-based dropdown parameter to the key of the query its values come from.
+type QuerySpec struct {
+	Key            string                `json:"key"`
+	Name           string                `json:"name"`
+	Description    string                `json:"description,omitempty"`
+	DataSource     string                `json:"data_source"`
+	Query          string                `json:"query"`
+	Options        redash.QueryOptions   `json:"options"`
+	Dropdowns      map[string]string     `json:"dropdowns,omitempty"`
+	Schedule       *redash.QuerySchedule `json:"schedule,omitempty"`
+	Tags           []string              `json:"tags,omitempty"`
+	Visualizations []VisualizationSpec   `json:"visualizations,omitempty"`
+}
+
+// VisualizationSpec describes a visualization of a query
+type VisualizationSpec struct {
+	Key         string      `json:"key"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Type        string      `json:"type"`
+	Options     interface{} `json:"options"`
+}
+
+// WidgetSpec describes a widget, either a visualization (Query and Visualization keys) or a text widget
+type WidgetSpec struct {
+	Query             string                                   `json:"query,omitempty"`
+	Visualization     string                                   `json:"visualization,omitempty"`
+	Text              string                                   `json:"text,omitempty"`
+	IsHidden          bool                                     `json:"is_hidden,omitempty"`
+	Position          redash.WidgetPosition                    `json:"position"`
+	ParameterMappings map[string]redash.WidgetParameterMapping `json:"parameter_mappings,omitempty"`
+}
+
+// IsText returns true for text widgets
+func (w *WidgetSpec) IsText() bool {
+	return w.Visualization == ""
+}
+
+// FindQuery returns the query with the given key
+func (d *DashboardSpec) FindQuery(key string) (*QuerySpec, bool) {
+	for i := range d.Queries {
+		if d.Queries[i].Key == key {
+			return &d.Queries[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindVisualization returns the visualization with the given key
+func (q *QuerySpec) FindVisualization(key string) (*VisualizationSpec, bool) {
+	for i := range q.Visualizations {
+		if q.Visualizations[i].Key == key {
+			return &q.Visualizations[i], true
+		}
+	}
+	return nil, false
+}
+
+// Validate checks the document is complete and every widget reference resolves
+func (d *Document) Validate() error {
+	if d.Version != DocumentVersion {
+		return fmt.Errorf("unsupported document version %d", d.Version)
+	}
+	if d.Dashboard.Name == "" {
+		return fmt.Errorf("dashboard has no name")
+	}
+
+	queries := map[string]bool{}
+	for _, query := range d.Dashboard.Queries {
+		if query.Key == "" || queries[query.Key] {
+			return fmt.Errorf("query %q has an empty or duplicate key", query.Name)
+		}
+		queries[query.Key] = true
+		if query.DataSource == "" {
+			return fmt.Errorf("query %s has no data source", query.Key)
+		}
+
+		visualizations := map[string]bool{}
+		for _, visualization := range query.Visualizations {
+			if visualization.Key == "" || visualizations[visualization.Key] {
+				return fmt.Errorf("visualization %q of query %s has an empty or duplicate key", visualization.Name, query.Key)
+			}
+			visualizations[visualization.Key] = true
+		}
+	}
+
+	for _, query := range d.Dashboard.Queries {
+		if err := query.validateDropdowns(queries); err != nil {
+			return err
+		}
+	}
+
+	for i, widget := range d.Dashboard.Widgets {
+		if widget.IsText() {
+			if widget.Query != "" {
+				return fmt.Errorf("widget %d references query %s without a visualization", i, widget.Query)
+			}
+			continue
+		}
+		query, ok := d.Dashboard.FindQuery(widget.Query)
+		if !ok {
+			return fmt.Errorf("widget %d references unknown query %q", i, widget.Query)
+		}
+		if _, ok := query.FindVisualization(widget.Visualization); !ok {
+			return fmt.Errorf("widget %d references unknown visualization %q of query %s", i, widget.Visualization, widget.Query)
+		}
+	}
+
+	return nil
+}
+
+// validateDropdowns checks every dropdown references a parameter of the query and a query of
+// the document, instead of a query ID of the instance it was exported from
+func (q *QuerySpec) validateDropdowns(queries map[string]bool) error {
+	parameters := map[string]bool{}
+	for _, parameter := range q.Options.Parameters {
+		if parameter.QueryID != 0 {
+			return fmt.Errorf("parameter %s of query %s reads its values from query %d, reference that query by key in dropdowns", parameter.Name, q.Key, parameter.QueryID)
+		}
+		parameters[parameter.Name] = true
+	}
+
+	for name, key := range q.Dropdowns {
+		if !parameters[name] {
+			return fmt.Errorf("query %s has a dropdown for unknown parameter %q", q.Key, name)
+		}
+		if !queries[key] {
+			return fmt.Errorf("dropdown %s of query %s references unknown query %q", name, q.Key, key)
+		}
+	}
+	return nil
+}
+
+// Formats supported by Encode and Decode
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Encode writes the document as indented JSON or block style YAML, keeping field order
+func (d *Document) Encode(format string) ([]byte, error) {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatJSON:
+		return append(data, '\n'), nil
+	case FormatYAML:
+		return JSONToYAML(data)
+	}
+
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// Decode reads a document written by Encode, YAML is a superset of JSON so both formats are accepted
+func Decode(data []byte) (*Document, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	document := Document{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return &document, document.Validate()
+}
+
+// JSONToYAML converts JSON to YAML without going through maps, which would sort keys.
+// Multiline strings, such as query text, are written as literal blocks.
+func JSONToYAML(data []byte) ([]byte, error) {
+	node := yaml.Node{}
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	buffer := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify returns a lowercase dash separated key for a name
+func slugify(name string) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug == "" {
+		return "unnamed"
+	}
+	return slug
+}
+
+// uniqueKey returns slugify(name), suffixed with a counter when it is already used
+func uniqueKey(name string, used map[string]bool) string {
+	base := slugify(name)
+	key := base
+	for i := 2; used[key]; i++ {
+		key = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[key] = true
+	return key
+}
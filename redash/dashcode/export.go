@@ -0,0 +1,206 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package dashcode
+
+import (
+	"sort"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// Export reads a dashboard with its widgets, visualizations and queries into a Document.
+// Widgets are ordered top to bottom, left to right so documents diff cleanly. Queries that
+// only feed query-based dropdowns are read as well and added without visualizations.
+func Export(client *redash.Client, slug string) (*Document, error) {
+	dashboard, err := client.GetDashboard(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSources, err := client.GetDataSources()
+	if err != nil {
+		return nil, err
+	}
+	dataSourceNames := map[int]string{}
+	for _, dataSource := range *dataSources {
+		dataSourceNames[dataSource.ID] = dataSource.Name
+	}
+
+	document, keys := fromDashboard(dashboard, dataSourceNames)
+	for {
+		id := unresolvedDropdown(&document.Dashboard)
+		if id == 0 {
+			return document, nil
+		}
+
+		query, err := client.GetQuery(id)
+		if err != nil {
+			return nil, err
+		}
+		keys.addQuery(&document.Dashboard, &redash.QueryDashboard{
+			ID:           query.ID,
+			Name:         query.Name,
+			Description:  query.Description,
+			DataSourceID: query.DataSourceID,
+			Query:        query.Query,
+			Options:      query.Options,
+			Tags:         query.Tags,
+			Schedule:     query.Schedule,
+		}, dataSourceNames)
+	}
+}
+
+// FromDashboard converts a dashboard into a Document, dataSourceNames maps data source IDs to their names.
+// Dropdowns reading from queries the dashboard does not display keep their query ID, which Validate
+// rejects, Export reads those queries into the document.
+func FromDashboard(dashboard *redash.Dashboard, dataSourceNames map[int]string) *Document {
+	document, _ := fromDashboard(dashboard, dataSourceNames)
+	return document
+}
+
+// queryKeys assigns document keys to query IDs
+type queryKeys struct {
+	keys map[int]string
+	used map[string]bool
+}
+
+// addQuery adds a query to the document, resolving the dropdowns that read from it
+func (k *queryKeys) addQuery(spec *DashboardSpec, query *redash.QueryDashboard, dataSourceNames map[int]string) string {
+	key := uniqueKey(query.Name, k.used)
+	k.keys[query.ID] = key
+	spec.Queries = append(spec.Queries, querySpec(key, query, dataSourceNames))
+	k.resolveDropdowns(spec)
+	return key
+}
+
+// resolveDropdowns replaces the query IDs of dropdowns by the keys of queries in the document
+func (k *queryKeys) resolveDropdowns(spec *DashboardSpec) {
+	for i := range spec.Queries {
+		query := &spec.Queries[i]
+		for j := range query.Options.Parameters {
+			parameter := &query.Options.Parameters[j]
+			key, ok := k.keys[parameter.QueryID]
+			if parameter.QueryID == 0 || !ok {
+				continue
+			}
+			if query.Dropdowns == nil {
+				query.Dropdowns = map[string]string{}
+			}
+			query.Dropdowns[parameter.Name] = key
+			parameter.QueryID = 0
+		}
+	}
+}
+
+// unresolvedDropdown returns the ID of a query a dropdown reads from that is not in the document yet, 0 if there is none
+func unresolvedDropdown(spec *DashboardSpec) int {
+	for _, query := range spec.Queries {
+		for _, parameter := range query.Options.Parameters {
+			if parameter.QueryID != 0 {
+				return parameter.QueryID
+			}
+		}
+	}
+	return 0
+}
+
+func fromDashboard(dashboard *redash.Dashboard, dataSourceNames map[int]string) (*Document, *queryKeys) {
+	spec := DashboardSpec{
+		Name:                    dashboard.Name,
+		Slug:                    dashboard.Slug,
+		Tags:                    dashboard.Tags,
+		IsDraft:                 dashboard.IsDraft,
+		DashboardFiltersEnabled: dashboard.DashboardFiltersEnabled,
+	}
+
+	widgets := append([]redash.WidgetDashboard{}, dashboard.Widgets...)
+	sort.SliceStable(widgets, func(i, j int) bool {
+		a, b := widgets[i].Options.Position, widgets[j].Options.Position
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		return a.Col < b.Col
+	})
+
+	keys := &queryKeys{keys: map[int]string{}, used: map[string]bool{}}
+	visualizationKeys := map[int]string{}
+	usedVisualizationKeys := map[string]map[string]bool{}
+
+	for _, widget := range widgets {
+		widgetSpec := WidgetSpec{
+			IsHidden:          widget.Options.IsHidden,
+			Position:          widget.Options.Position,
+			ParameterMappings: widget.Options.ParameterMappings,
+		}
+
+		if widget.IsText() {
+			widgetSpec.Text = widget.Text
+			spec.Widgets = append(spec.Widgets, widgetSpec)
+			continue
+		}
+
+		visualization := widget.Visualization
+		query := visualization.Query
+
+		queryKey, exists := keys.keys[query.ID]
+		if !exists {
+			queryKey = keys.addQuery(&spec, &query, dataSourceNames)
+			usedVisualizationKeys[queryKey] = map[string]bool{}
+		}
+
+		visualizationKey, exists := visualizationKeys[visualization.ID]
+		if !exists {
+			visualizationKey = uniqueKey(visualization.Name, usedVisualizationKeys[queryKey])
+			visualizationKeys[visualization.ID] = visualizationKey
+			querySpec, _ := spec.FindQuery(queryKey)
+			querySpec.Visualizations = append(querySpec.Visualizations, VisualizationSpec{
+				Key:         visualizationKey,
+				Name:        visualization.Name,
+				Description: visualization.Description,
+				Type:        visualization.Type,
+				Options:     visualization.Options,
+			})
+		}
+
+		widgetSpec.Query = queryKey
+		widgetSpec.Visualization = visualizationKey
+		spec.Widgets = append(spec.Widgets, widgetSpec)
+	}
+
+	return &Document{Version: DocumentVersion, Dashboard: spec}, keys
+}
+
+func querySpec(key string, query *redash.QueryDashboard, dataSourceNames map[int]string) QuerySpec {
+	options := query.Options
+	options.Parameters = append([]redash.QueryOptionsParameter{}, options.Parameters...)
+	for i := range options.Parameters {
+		// The query's own ID, Redash fills it back in
+		options.Parameters[i].ParentQueryId = 0
+	}
+
+	spec := QuerySpec{
+		Key:         key,
+		Name:        query.Name,
+		Description: query.Description,
+		DataSource:  dataSourceNames[query.DataSourceID],
+		Query:       query.Query,
+		Options:     options,
+		Tags:        query.Tags,
+	}
+	if schedule := query.Schedule; schedule.Interval != 0 || schedule.Time != "" || schedule.DayOfWeek != "" || schedule.Until != nil {
+		spec.Schedule = &schedule
+	}
+
+	return spec
+}
@@ -0,0 +1,430 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package dashcode
+
+import (
+	"fmt"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// ImportResult summarises what Import changed
+type ImportResult struct {
+	DashboardID int
+	Slug        string
+
+	Created []string
+	Updated []string
+	Deleted []string
+}
+
+func (r *ImportResult) created(format string, args ...interface{}) {
+	r.Created = append(r.Created, fmt.Sprintf(format, args...))
+}
+
+func (r *ImportResult) updated(format string, args ...interface{}) {
+	r.Updated = append(r.Updated, fmt.Sprintf(format, args...))
+}
+
+func (r *ImportResult) deleted(format string, args ...interface{}) {
+	r.Deleted = append(r.Deleted, fmt.Sprintf(format, args...))
+}
+
+// FindDashboardBySlug walks the dashboard list for a dashboard with the given slug, nil if there is none
+func FindDashboardBySlug(client *redash.Client, slug string) (*redash.Dashboard, error) {
+	items, err := listDashboards(client, func(item *redash.DashboardListItem) bool { return item.Slug == slug })
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return client.GetDashboard(items[0].Slug)
+}
+
+// findDashboardByName returns the only dashboard with the given name, nil if there is none
+func findDashboardByName(client *redash.Client, name string) (*redash.Dashboard, error) {
+	items, err := listDashboards(client, func(item *redash.DashboardListItem) bool { return item.Name == name })
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	if len(items) > 1 {
+		return nil, fmt.Errorf("%d dashboards are named %q, set the slug of the document to pick one", len(items), name)
+	}
+	return client.GetDashboard(items[0].Slug)
+}
+
+// listDashboards walks the dashboard list for the dashboards matching fn
+func listDashboards(client *redash.Client, fn func(item *redash.DashboardListItem) bool) ([]redash.DashboardListItem, error) {
+	pageSize := 100
+	matching := []redash.DashboardListItem{}
+	for page := 1; ; page++ {
+		dashboards, err := client.GetDashboards(page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range dashboards.Results {
+			if fn(&dashboards.Results[i]) {
+				matching = append(matching, dashboards.Results[i])
+			}
+		}
+
+		if len(dashboards.Results) == 0 || page*pageSize >= dashboards.Count {
+			return matching, nil
+		}
+	}
+}
+
+// Import creates or updates the dashboard described by a Document. An existing dashboard is
+// found by slug, or by name when no dashboard has the slug, as Redash may not keep the slug
+// asked for when it creates a dashboard. Its queries and visualizations are matched by name and its widgets by
+// visualization (text widgets in order). Widgets missing from the document are deleted,
+// queries that are no longer displayed are left untouched.
+func Import(client *redash.Client, document *Document) (*ImportResult, error) {
+	if err := document.Validate(); err != nil {
+		return nil, err
+	}
+	spec := document.Dashboard
+	result := &ImportResult{}
+
+	dataSources, err := client.GetDataSources()
+	if err != nil {
+		return nil, err
+	}
+	dataSourceIDs := map[string]int{}
+	for _, dataSource := range *dataSources {
+		dataSourceIDs[dataSource.Name] = dataSource.ID
+	}
+
+	var dashboard *redash.Dashboard
+	if spec.Slug != "" {
+		dashboard, err = FindDashboardBySlug(client, spec.Slug)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dashboard == nil {
+		dashboard, err = findDashboardByName(client, spec.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dashboard == nil {
+		dashboard, err = client.CreateDashboard(&redash.DashboardCreatePayload{Name: spec.Name})
+		if err != nil {
+			return nil, err
+		}
+		result.created("dashboard %s", dashboard.Slug)
+	}
+	result.DashboardID = dashboard.ID
+
+	existingQueries := map[string]int{}
+	for _, widget := range dashboard.Widgets {
+		if !widget.IsText() {
+			existingQueries[widget.Visualization.Query.Name] = widget.Visualization.Query.ID
+		}
+	}
+
+	queryIDs := existingQueryIDs(dashboard, &spec, existingQueries)
+	visualizationIDs := map[string]map[string]int{}
+	incomplete := []int{}
+	for _, i := range importOrder(spec.Queries) {
+		querySpec := &spec.Queries[i]
+		dataSourceID, ok := dataSourceIDs[querySpec.DataSource]
+		if !ok {
+			return result, fmt.Errorf("query %s uses unknown data source %q", querySpec.Key, querySpec.DataSource)
+		}
+
+		options, complete := dropdownOptions(querySpec, queryIDs)
+		query, err := importQuery(client, querySpec, options, dataSourceID, queryIDs[querySpec.Key], result)
+		if err != nil {
+			return result, err
+		}
+		queryIDs[querySpec.Key] = query.ID
+		if !complete {
+			incomplete = append(incomplete, i)
+		}
+
+		visualizationIDs[querySpec.Key], err = importVisualizations(client, querySpec, query, result)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Dropdowns reading from a query imported later, or from their own query, are set once every query exists
+	for _, i := range incomplete {
+		querySpec := &spec.Queries[i]
+		options, _ := dropdownOptions(querySpec, queryIDs)
+		_, err := importQuery(client, querySpec, options, dataSourceIDs[querySpec.DataSource], queryIDs[querySpec.Key], result)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if err := importWidgets(client, dashboard, &spec, visualizationIDs, result); err != nil {
+		return result, err
+	}
+
+	slug := spec.Slug
+	if slug == "" {
+		slug = dashboard.Slug
+	}
+	updated, err := client.UpdateDashboard(dashboard.ID, &redash.DashboardUpdatePayload{
+		Name:                    spec.Name,
+		Slug:                    slug,
+		IsFavorite:              dashboard.IsFavorite,
+		IsDraft:                 spec.IsDraft,
+		DashboardFiltersEnabled: spec.DashboardFiltersEnabled,
+		Tags:                    spec.Tags,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Slug = updated.Slug
+	result.updated("dashboard %s", updated.Slug)
+
+	return result, nil
+}
+
+// existingQueryIDs returns the IDs of the queries of the document already on the dashboard, by key.
+// Displayed queries are matched by name, the queries feeding their dropdowns through the dropdowns.
+func existingQueryIDs(dashboard *redash.Dashboard, spec *DashboardSpec, existingQueries map[string]int) map[string]int {
+	ids := map[string]int{}
+	byName := map[string]*QuerySpec{}
+	for i := range spec.Queries {
+		query := &spec.Queries[i]
+		byName[query.Name] = query
+		if id, ok := existingQueries[query.Name]; ok {
+			ids[query.Key] = id
+		}
+	}
+
+	for _, widget := range dashboard.Widgets {
+		query, ok := byName[widget.Visualization.Query.Name]
+		if widget.IsText() || !ok {
+			continue
+		}
+		for _, parameter := range widget.Visualization.Query.Options.Parameters {
+			if key, ok := query.Dropdowns[parameter.Name]; ok && parameter.QueryID != 0 && ids[key] == 0 {
+				ids[key] = parameter.QueryID
+			}
+		}
+	}
+	return ids
+}
+
+// importOrder orders queries so that the queries dropdowns read from come first. Queries
+// in a cycle are imported in document order.
+func importOrder(queries []QuerySpec) []int {
+	order := []int{}
+	done := map[string]bool{}
+	for len(order) < len(queries) {
+		next := -1
+		for i, query := range queries {
+			if done[query.Key] {
+				continue
+			}
+			if next == -1 {
+				next = i
+			}
+			ready := true
+			for _, key := range query.Dropdowns {
+				ready = ready && (done[key] || key == query.Key)
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		order = append(order, next)
+		done[queries[next].Key] = true
+	}
+	return order
+}
+
+// dropdownOptions returns the options of a query with the IDs of the queries its dropdowns read from,
+// complete is false when one of them is not known yet
+func dropdownOptions(spec *QuerySpec, ids map[string]int) (options redash.QueryOptions, complete bool) {
+	complete = true
+	if spec.Options.Parameters != nil {
+		options.Parameters = []redash.QueryOptionsParameter{}
+	}
+	for _, parameter := range spec.Options.Parameters {
+		if key, ok := spec.Dropdowns[parameter.Name]; ok {
+			parameter.QueryID = ids[key]
+			complete = complete && parameter.QueryID != 0
+		}
+		options.Parameters = append(options.Parameters, parameter)
+	}
+	return options, complete
+}
+
+func importQuery(client *redash.Client, spec *QuerySpec, options redash.QueryOptions, dataSourceID, existingID int, result *ImportResult) (*redash.Query, error) {
+	if existingID == 0 {
+		created, err := client.CreateQuery(&redash.QueryCreatePayload{
+			Name:         spec.Name,
+			Description:  spec.Description,
+			DataSourceID: dataSourceID,
+			Query:        spec.Query,
+			Options:      options,
+			Tags:         spec.Tags,
+			Schedule:     spec.Schedule,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.created("query %s", spec.Key)
+
+		return client.GetQuery(created.ID)
+	}
+
+	existing, err := client.GetQuery(existingID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.UpdateQuery(existingID, &redash.QueryUpdatePayload{
+		Name:         spec.Name,
+		Description:  spec.Description,
+		DataSourceID: dataSourceID,
+		Query:        spec.Query,
+		Options:      options,
+		IsDraft:      existing.IsDraft,
+		Version:      existing.Version,
+		Tags:         spec.Tags,
+		Schedule:     spec.Schedule,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.updated("query %s", spec.Key)
+
+	return existing, nil
+}
+
+func importVisualizations(client *redash.Client, spec *QuerySpec, query *redash.Query, result *ImportResult) (map[string]int, error) {
+	existing := map[string]int{}
+	for _, visualization := range query.Visualizations {
+		if _, seen := existing[visualization.Name]; !seen {
+			existing[visualization.Name] = visualization.ID
+		}
+	}
+
+	ids := map[string]int{}
+	for _, visualization := range spec.Visualizations {
+		if id, ok := existing[visualization.Name]; ok {
+			_, err := client.UpdateVisualization(id, &redash.VisualizationUpdatePayload{
+				Name:        visualization.Name,
+				Description: visualization.Description,
+				Type:        visualization.Type,
+				Options:     visualization.Options,
+			})
+			if err != nil {
+				return nil, err
+			}
+			ids[visualization.Key] = id
+			result.updated("visualization %s/%s", spec.Key, visualization.Key)
+			continue
+		}
+
+		created, err := client.CreateVisualization(&redash.VisualizationCreatePayload{
+			Name:        visualization.Name,
+			Description: visualization.Description,
+			Type:        visualization.Type,
+			Options:     visualization.Options,
+			QueryId:     query.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ids[visualization.Key] = created.ID
+		result.created("visualization %s/%s", spec.Key, visualization.Key)
+	}
+
+	return ids, nil
+}
+
+func importWidgets(client *redash.Client, dashboard *redash.Dashboard, spec *DashboardSpec, visualizationIDs map[string]map[string]int, result *ImportResult) error {
+	byVisualization := map[int]redash.WidgetDashboard{}
+	texts := []redash.WidgetDashboard{}
+	for _, widget := range dashboard.Widgets {
+		if widget.IsText() {
+			texts = append(texts, widget)
+		} else if _, seen := byVisualization[widget.Visualization.ID]; !seen {
+			byVisualization[widget.Visualization.ID] = widget
+		}
+	}
+
+	kept := map[int]bool{}
+	for i, widgetSpec := range spec.Widgets {
+		options := redash.WidgetOptions{
+			IsHidden:          widgetSpec.IsHidden,
+			ParameterMappings: widgetSpec.ParameterMappings,
+			Position:          widgetSpec.Position,
+		}
+
+		var visualizationID *int
+		var existing *redash.WidgetDashboard
+		if widgetSpec.IsText() {
+			if len(texts) > 0 {
+				existing = &texts[0]
+				texts = texts[1:]
+			}
+		} else {
+			id := visualizationIDs[widgetSpec.Query][widgetSpec.Visualization]
+			visualizationID = &id
+			if widget, ok := byVisualization[id]; ok {
+				existing = &widget
+				delete(byVisualization, id)
+			}
+		}
+
+		if existing != nil {
+			_, err := client.UpdateWidget(existing.ID, &redash.WidgetUpdatePayload{
+				Text:            widgetSpec.Text,
+				Width:           1,
+				VisualizationID: visualizationID,
+				Options:         options,
+			})
+			if err != nil {
+				return err
+			}
+			kept[existing.ID] = true
+			result.updated("widget %d", i)
+			continue
+		}
+
+		_, err := client.CreateWidget(&redash.WidgetCreatePayload{
+			DashboardID:     dashboard.ID,
+			Text:            widgetSpec.Text,
+			Width:           1,
+			VisualizationID: visualizationID,
+			Options:         options,
+		})
+		if err != nil {
+			return err
+		}
+		result.created("widget %d", i)
+	}
+
+	for _, widget := range dashboard.Widgets {
+		if kept[widget.ID] {
+			continue
+		}
+		if err := client.DeleteWidget(widget.ID); err != nil {
+			return err
+		}
+		result.deleted("widget %d", widget.ID)
+	}
+
+	return nil
+}
@@ -19,8 +19,6 @@ import (
 	"io/ioutil"
 	"net/url"
 	"strconv"
-
-	log "github.com/sirupsen/logrus"
 )
 
 // DataSource struct
@@ -37,6 +35,12 @@ type DataSource struct {
 	Groups             map[int]bool           `json:"groups,omitempty"`
 }
 
+// DataSourceTestResult struct for the outcome of a connection test
+type DataSourceTestResult struct {
+	Message string `json:"message"`
+	Ok      bool   `json:"ok"`
+}
+
 // DataSourceType struct
 type DataSourceType struct {
 	Type                string `json:"type"`
@@ -133,7 +137,7 @@ func (c *Client) SanitizeDataSourceOptions(dataSource *DataSource) (*DataSource,
 
 	dataSourceTypes, err := c.GetDataSourceTypes()
 	if err != nil {
-		fmt.Println(err)
+		c.log(LogLevelWarn, "Data source types unavailable, options are not checked", map[string]interface{}{"error": err.Error()})
 	}
 
 	for _, dst := range dataSourceTypes {
@@ -152,7 +156,7 @@ func (c *Client) SanitizeDataSourceOptions(dataSource *DataSource) (*DataSource,
 				_, exists := dst.ConfigurationSchema.Properties[propName]
 
 				if whitelistedProps[propName] {
-					log.Warn(fmt.Sprintf("[WARN] Whitelisted field (%s)", propName))
+					c.log(LogLevelWarn, "Whitelisted data source field", map[string]interface{}{"field": propName})
 					continue
 				}
 
@@ -161,7 +165,7 @@ func (c *Client) SanitizeDataSourceOptions(dataSource *DataSource) (*DataSource,
 						return nil, fmt.Errorf("Invalid field (%s) for type: %s", propName, dataSource.Type)
 					}
 
-					log.Warn(fmt.Sprintf("[WARN] Ignoring invalid field (%s) for type: %s", propName, dataSource.Type))
+					c.log(LogLevelWarn, "Ignoring invalid data source field", map[string]interface{}{"field": propName, "type": dataSource.Type})
 					delete((*dataSource).Options, propName)
 					continue
 				}
@@ -274,3 +278,29 @@ func (c *Client) DeleteDataSource(id int) error {
 
 	return nil
 }
+
+// TestDataSource checks Redash can connect to a specific DataSource
+func (c *Client) TestDataSource(id int) (*DataSourceTestResult, error) {
+	path := "/api/data_sources/" + strconv.Itoa(id) + "/test"
+
+	query := url.Values{}
+	response, err := c.post(path, "", query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := DataSourceTestResult{}
+
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
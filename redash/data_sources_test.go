@@ -0,0 +1,44 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestDataSource(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/data_sources/1/test",
+		httpmock.NewStringResponder(200, `{"message": "success", "ok": true}`))
+
+	result, err := c.TestDataSource(1)
+	assert.Nil(err)
+	assert.True(result.Ok)
+	assert.Equal("success", result.Message)
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/data_sources/2/test",
+		httpmock.NewStringResponder(200, `{"message": "could not connect to server", "ok": false}`))
+
+	result, err = c.TestDataSource(2)
+	assert.Nil(err)
+	assert.False(result.Ok)
+}
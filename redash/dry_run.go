@@ -0,0 +1,134 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Change is a mutation captured instead of being sent in dry-run mode, Path includes the organization prefix.
+// Secrets in Payload, such as data source passwords, are replaced by RedactedValue.
+type Change struct {
+	Method  string     `json:"method"`
+	Path    string     `json:"path"`
+	Query   url.Values `json:"query,omitempty"`
+	Payload string     `json:"payload,omitempty"`
+	Time    time.Time  `json:"time"`
+}
+
+// String renders a change as "METHOD path payload"
+func (c Change) String() string {
+	path := c.Path
+	if len(c.Query) > 0 {
+		path += "?" + c.Query.Encode()
+	}
+	if c.Payload == "" {
+		return c.Method + " " + path
+	}
+	return c.Method + " " + path + " " + c.Payload
+}
+
+// ChangeLog collects the changes of a dry run, it is shared by the copies of a Client
+type ChangeLog struct {
+	mutex   sync.Mutex
+	changes []Change
+	lastID  int
+}
+
+// Changes returns the captured changes in the order they were made
+func (l *ChangeLog) Changes() []Change {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]Change{}, l.changes...)
+}
+
+// Reset forgets the captured changes and restarts the synthesized IDs at -1
+func (l *ChangeLog) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.changes = nil
+	l.lastID = 0
+}
+
+// record captures a change and returns the ID synthesized for it, negative for created objects
+func (l *ChangeLog) record(change Change, create bool) int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.changes = append(l.changes, change)
+	if !create {
+		return 0
+	}
+	l.lastID--
+	return l.lastID
+}
+
+// Changes returns the changes captured in dry-run mode
+func (c *Client) Changes() []Change {
+	if c.Config.ChangeLog == nil {
+		return nil
+	}
+	return c.Config.ChangeLog.Changes()
+}
+
+var (
+	createPath = regexp.MustCompile(`^/api/[a-z_]+$`)
+	objectPath = regexp.MustCompile(`^/api/[a-z_]+/(\d+)$`)
+)
+
+// dryRun captures a mutation and synthesizes its response. The payload is echoed back with
+// an id: the one of the path for updates, a negative one for creates, so callers decoding
+// the response keep working. Only the captured change is redacted, the response echoes the
+// payload as sent.
+func (c *Client) dryRun(method, path, requestPath, body string, query url.Values) *http.Response {
+	change := Change{Method: method, Path: requestPath, Payload: RedactJSON(body), Time: time.Now().UTC()}
+	if len(query) > 0 {
+		change.Query = cloneValues(query)
+	}
+
+	create := method == http.MethodPost && createPath.MatchString(path)
+	id := c.Config.ChangeLog.record(change, create)
+	if match := objectPath.FindStringSubmatch(path); match != nil {
+		id, _ = strconv.Atoi(match[1])
+	}
+
+	c.log(LogLevelInfo, "Dry run, request not sent", map[string]interface{}{"method": method, "path": requestPath})
+
+	response := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(body), &response); err != nil || response == nil {
+		response = map[string]interface{}{}
+	}
+	if id != 0 {
+		response["id"] = id
+	}
+	synthesized, _ := json.Marshal(response)
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(string(synthesized))),
+		ContentLength: int64(len(synthesized)),
+	}
+}
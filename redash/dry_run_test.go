@@ -0,0 +1,85 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/1",
+		httpmock.NewStringResponder(200, `{"id": 1, "name": "Revenue", "data_source_id": 2}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources/types",
+		httpmock.NewStringResponder(200, `[{"type": "pg", "configuration_schema": {"properties": {"host": {"type": "string"}, "password": {"type": "string"}}, "secret": ["password"]}}]`))
+	httpmock.RegisterNoResponder(func(req *http.Request) (*http.Response, error) {
+		t.Errorf("unexpected %s request to %s", req.Method, req.URL)
+		return httpmock.NewStringResponse(500, ""), nil
+	})
+
+	c, err := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy", DryRun: true})
+	assert.Nil(err)
+
+	// Reads still reach the server
+	query, err := c.GetQuery(1)
+	assert.Nil(err)
+	assert.Equal("Revenue", query.Name)
+
+	created, err := c.CreateQuery(&QueryCreatePayload{Name: "Revenue copy", DataSourceID: query.DataSourceID, Query: "SELECT 1"})
+	assert.Nil(err)
+	assert.Equal(-1, created.ID)
+	assert.Equal("Revenue copy", created.Name)
+
+	updated, err := c.UpdateQuery(1, &QueryUpdatePayload{Name: "Revenue by day", DataSourceID: 2})
+	assert.Nil(err)
+	assert.Equal(1, updated.ID)
+	assert.Equal("Revenue by day", updated.Name)
+
+	assert.Nil(c.WithOrg("acme-eu").ArchiveQuery(1))
+	assert.Nil(c.DisableUser(7))
+	share, err := c.ShareDashboard(3)
+	assert.Nil(err)
+	assert.Equal("", share.PublicURL)
+
+	changes := c.Changes()
+	assert.Len(changes, 5)
+	assert.Equal("POST", changes[0].Method)
+	assert.Equal("/api/queries", changes[0].Path)
+	assert.Contains(changes[0].Payload, `"name":"Revenue copy"`)
+	assert.Equal("DELETE /acme-eu/api/queries/1", changes[2].String())
+	assert.Equal("POST /api/users/7/disable", changes[3].String())
+
+	// Secrets are redacted from the change log but not from the synthesized response
+	dataSource, err := c.CreateDataSource(&DataSource{Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"host": "db.acme", "password": "hunter2"}})
+	assert.Nil(err)
+	assert.Equal(-2, dataSource.ID)
+	assert.Equal("hunter2", dataSource.Options["password"])
+	changes = c.Changes()
+	assert.NotContains(changes[5].Payload, "hunter2")
+	assert.Contains(changes[5].Payload, `"password":"[REDACTED]"`)
+
+	c.Config.ChangeLog.Reset()
+	assert.Empty(c.Changes())
+
+	created, err = c.CreateQuery(&QueryCreatePayload{Name: "Revenue copy", DataSourceID: 2, Query: "SELECT 1"})
+	assert.Nil(err)
+	assert.Equal(-1, created.ID)
+}
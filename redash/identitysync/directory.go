@@ -0,0 +1,127 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package identitysync
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DirectoryUser is a user as exported from an external identity provider
+type DirectoryUser struct {
+	Email  string   `json:"email"`
+	Name   string   `json:"name"`
+	Groups []string `json:"groups"`
+}
+
+// Directory is the desired set of users and their group memberships
+type Directory struct {
+	Users []DirectoryUser `json:"users"`
+}
+
+// LoadJSON reads a Directory from a JSON document, either `{"users": [...]}` or a bare array of users
+func LoadJSON(r io.Reader) (*Directory, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	directory := Directory{}
+	if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+		err = json.Unmarshal(body, &directory.Users)
+	} else {
+		err = json.Unmarshal(body, &directory)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &directory, directory.validate()
+}
+
+// LoadCSV reads a Directory from a CSV export with an `email,name,groups` header.
+// Groups are separated by semicolons.
+func LoadCSV(r io.Reader) (*Directory, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("Missing email column in CSV header")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	directory := Directory{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		user := DirectoryUser{
+			Email: field(record, "email"),
+			Name:  field(record, "name"),
+		}
+		for _, group := range strings.Split(field(record, "groups"), ";") {
+			if group = strings.TrimSpace(group); group != "" {
+				user.Groups = append(user.Groups, group)
+			}
+		}
+		directory.Users = append(directory.Users, user)
+	}
+
+	return &directory, directory.validate()
+}
+
+func (d *Directory) validate() error {
+	seen := map[string]bool{}
+	for _, user := range d.Users {
+		if user.Email == "" {
+			return fmt.Errorf("Directory user without email: %q", user.Name)
+		}
+
+		email := normalizeEmail(user.Email)
+		if seen[email] {
+			return fmt.Errorf("Duplicate directory user: %s", user.Email)
+		}
+		seen[email] = true
+	}
+
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
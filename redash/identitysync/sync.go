@@ -0,0 +1,393 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Package identitysync reconciles Redash users and group memberships against
+// an export of an external identity provider
+package identitysync
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// ActionType is the kind of change a sync Action makes
+type ActionType string
+
+// Supported sync actions, in the order they are applied
+const (
+	CreateGroup  ActionType = "create_group"
+	CreateUser   ActionType = "create_user"
+	EnableUser   ActionType = "enable_user"
+	AddMember    ActionType = "add_member"
+	RemoveMember ActionType = "remove_member"
+	DisableUser  ActionType = "disable_user"
+)
+
+var actionOrder = map[ActionType]int{
+	CreateGroup:  0,
+	CreateUser:   1,
+	EnableUser:   2,
+	AddMember:    3,
+	RemoveMember: 4,
+	DisableUser:  5,
+}
+
+// Action is a single change in a Plan. UserID and GroupID are zero when the
+// object does not exist yet and is created by an earlier action of the plan.
+type Action struct {
+	Type    ActionType `json:"type"`
+	Email   string     `json:"email,omitempty"`
+	Name    string     `json:"name,omitempty"`
+	Group   string     `json:"group,omitempty"`
+	UserID  int        `json:"user_id,omitempty"`
+	GroupID int        `json:"group_id,omitempty"`
+}
+
+func (a Action) String() string {
+	switch a.Type {
+	case CreateGroup:
+		return fmt.Sprintf("%s %q", a.Type, a.Group)
+	case AddMember, RemoveMember:
+		return fmt.Sprintf("%s %s -> %q", a.Type, a.Email, a.Group)
+	default:
+		return fmt.Sprintf("%s %s", a.Type, a.Email)
+	}
+}
+
+// Plan is the ordered list of actions needed to match a Directory
+type Plan struct {
+	Actions []Action `json:"actions"`
+}
+
+// Empty returns true if Redash already matches the Directory
+func (p *Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// Options controls what the Syncer is allowed to manage
+type Options struct {
+	// DisableMissing disables active Redash users that are absent from the Directory
+	DisableMissing bool
+	// CreateGroups creates groups referenced by the Directory that do not exist in Redash
+	CreateGroups bool
+	// IgnoreGroups are never added to or removed from, "default" is always ignored
+	IgnoreGroups []string
+	// IgnoreUsers are emails never created, disabled or changed, e.g. break-glass admins
+	IgnoreUsers []string
+	// DryRun makes Apply record the plan in the audit log without calling Redash
+	DryRun bool
+}
+
+// Syncer computes and applies Plans against a Redash instance
+type Syncer struct {
+	Client  *redash.Client
+	Options Options
+
+	now func() time.Time
+}
+
+// NewSyncer returns a *Syncer for the given client
+func NewSyncer(client *redash.Client, options Options) *Syncer {
+	return &Syncer{Client: client, Options: options, now: time.Now}
+}
+
+type state struct {
+	users  map[string]*redash.UserListItem
+	groups map[string]redash.Group
+}
+
+func (s *Syncer) ignoredGroup(name string) bool {
+	if name == "default" {
+		return true
+	}
+	for _, group := range s.Options.IgnoreGroups {
+		if group == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Syncer) ignoredUser(email string) bool {
+	for _, user := range s.Options.IgnoreUsers {
+		if normalizeEmail(user) == email {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Syncer) load() (*state, error) {
+	current := &state{
+		users:  map[string]*redash.UserListItem{},
+		groups: map[string]redash.Group{},
+	}
+
+	groups, err := s.Client.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range *groups {
+		current.groups[group.Name] = group
+	}
+
+	collect := func(user *redash.UserListItem) error {
+		item := *user
+		current.users[normalizeEmail(user.Email)] = &item
+		return nil
+	}
+	if err := s.Client.EachUser(&redash.UserListOptions{}, collect); err != nil {
+		return nil, err
+	}
+	disabled := true
+	if err := s.Client.EachUser(&redash.UserListOptions{Disabled: &disabled}, collect); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// Plan compares the Directory with the current state of Redash and returns the actions needed to reconcile them
+func (s *Syncer) Plan(directory *Directory) (*Plan, error) {
+	current, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	plannedGroups := map[string]bool{}
+	desired := map[string]bool{}
+
+	for _, user := range directory.Users {
+		email := normalizeEmail(user.Email)
+		desired[email] = true
+		if s.ignoredUser(email) {
+			continue
+		}
+
+		existing, exists := current.users[email]
+		userID := 0
+		if exists {
+			userID = existing.ID
+		}
+
+		if !exists {
+			plan.Actions = append(plan.Actions, Action{Type: CreateUser, Email: user.Email, Name: user.Name})
+		} else if existing.IsDisabled {
+			plan.Actions = append(plan.Actions, Action{Type: EnableUser, Email: user.Email, UserID: userID})
+		}
+
+		wanted := map[string]bool{}
+		for _, name := range user.Groups {
+			if s.ignoredGroup(name) {
+				continue
+			}
+			wanted[name] = true
+
+			group, groupExists := current.groups[name]
+			if !groupExists {
+				if !s.Options.CreateGroups {
+					return nil, fmt.Errorf("Group %q for %s does not exist and CreateGroups is not set", name, user.Email)
+				}
+				if !plannedGroups[name] {
+					plan.Actions = append(plan.Actions, Action{Type: CreateGroup, Group: name})
+					plannedGroups[name] = true
+				}
+			}
+
+			if exists && existing.InGroup(nil, []string{name}) {
+				continue
+			}
+			plan.Actions = append(plan.Actions, Action{Type: AddMember, Email: user.Email, Group: name, UserID: userID, GroupID: group.ID})
+		}
+
+		if !exists {
+			continue
+		}
+		for _, group := range existing.Groups {
+			if wanted[group.Name] || s.ignoredGroup(group.Name) {
+				continue
+			}
+			plan.Actions = append(plan.Actions, Action{Type: RemoveMember, Email: user.Email, Group: group.Name, UserID: userID, GroupID: group.ID})
+		}
+	}
+
+	if s.Options.DisableMissing {
+		for email, user := range current.users {
+			if desired[email] || user.IsDisabled || s.ignoredUser(email) {
+				continue
+			}
+			plan.Actions = append(plan.Actions, Action{Type: DisableUser, Email: user.Email, UserID: user.ID})
+		}
+	}
+
+	sort.SliceStable(plan.Actions, func(i, j int) bool {
+		a, b := plan.Actions[i], plan.Actions[j]
+		if actionOrder[a.Type] != actionOrder[b.Type] {
+			return actionOrder[a.Type] < actionOrder[b.Type]
+		}
+		if a.Email != b.Email {
+			return a.Email < b.Email
+		}
+		return a.Group < b.Group
+	})
+
+	return plan, nil
+}
+
+// AuditEntry records the outcome of a single Action
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Action Action    `json:"action"`
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Audit statuses
+const (
+	StatusApplied = "applied"
+	StatusPlanned = "planned"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Result is the outcome of applying a Plan
+type Result struct {
+	DryRun bool         `json:"dry_run"`
+	Audit  []AuditEntry `json:"audit"`
+}
+
+// Failed returns the audit entries of actions that failed or were skipped because a dependency failed
+func (r *Result) Failed() []AuditEntry {
+	failed := []AuditEntry{}
+	for _, entry := range r.Audit {
+		if entry.Status == StatusFailed || entry.Status == StatusSkipped {
+			failed = append(failed, entry)
+		}
+	}
+	return failed
+}
+
+// Err returns an error summarising failed actions, or nil if everything was applied
+func (r *Result) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d identity sync actions failed, first: %s: %s", len(failed), len(r.Audit), failed[0].Action, failed[0].Error)
+}
+
+// WriteAudit writes the audit log as JSON lines
+func (r *Result) WriteAudit(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range r.Audit {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply runs the actions of a Plan in order. A failed action does not stop the
+// run, but actions depending on a user or group that failed to be created are skipped.
+func (s *Syncer) Apply(plan *Plan) *Result {
+	result := &Result{DryRun: s.Options.DryRun}
+	userIDs := map[string]int{}
+	groupIDs := map[string]int{}
+	failedUsers := map[string]bool{}
+	failedGroups := map[string]bool{}
+
+	record := func(action Action, status string, err error) {
+		entry := AuditEntry{Time: s.now(), Action: action, Status: status}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		result.Audit = append(result.Audit, entry)
+	}
+
+	for _, action := range plan.Actions {
+		email := normalizeEmail(action.Email)
+		if action.UserID == 0 {
+			action.UserID = userIDs[email]
+		}
+		if action.GroupID == 0 {
+			action.GroupID = groupIDs[action.Group]
+		}
+
+		if s.Options.DryRun {
+			record(action, StatusPlanned, nil)
+			continue
+		}
+
+		if failedUsers[email] || failedGroups[action.Group] {
+			record(action, StatusSkipped, fmt.Errorf("Depends on a failed action"))
+			continue
+		}
+
+		var err error
+		switch action.Type {
+		case CreateGroup:
+			var group *redash.Group
+			group, err = s.Client.CreateGroup(&redash.GroupCreatePayload{Name: action.Group})
+			if err == nil {
+				groupIDs[action.Group] = group.ID
+				action.GroupID = group.ID
+			} else {
+				failedGroups[action.Group] = true
+			}
+		case CreateUser:
+			var user *redash.User
+			user, err = s.Client.CreateUser(&redash.UserCreatePayload{Name: action.Name, Email: action.Email})
+			if err == nil {
+				userIDs[email] = user.ID
+				action.UserID = user.ID
+			} else {
+				failedUsers[email] = true
+			}
+		case EnableUser:
+			_, err = s.Client.EnableUser(action.UserID)
+		case AddMember:
+			err = s.Client.GroupAddUser(action.GroupID, action.UserID)
+		case RemoveMember:
+			err = s.Client.GroupRemoveUser(action.GroupID, action.UserID)
+		case DisableUser:
+			err = s.Client.DisableUser(action.UserID)
+		default:
+			err = fmt.Errorf("Unknown action type: %s", action.Type)
+		}
+
+		if err != nil {
+			record(action, StatusFailed, err)
+			continue
+		}
+		record(action, StatusApplied, nil)
+	}
+
+	return result
+}
+
+// Sync plans and applies in one step
+func (s *Syncer) Sync(directory *Directory) (*Plan, *Result, error) {
+	plan, err := s.Plan(directory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := s.Apply(plan)
+	return plan, result, result.Err()
+}
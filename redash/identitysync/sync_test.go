@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package identitysync
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func registerState() {
+	httpmock.RegisterResponder("GET", "https://com.acme/api/groups",
+		httpmock.NewStringResponder(200, `[{"id": 1, "name": "admin"}, {"id": 2, "name": "default"}, {"id": 3, "name": "analysts"}]`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/users?page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 2, "page": 1, "page_size": 100, "results": [
+			{"id": 10, "email": "alice@example.com", "groups": [{"id": 1, "name": "admin"}, {"id": 2, "name": "default"}]},
+			{"id": 11, "email": "bob@example.com", "groups": [{"id": 2, "name": "default"}, {"id": 3, "name": "analysts"}]}
+		]}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/users?disabled=true&page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 1, "page": 1, "page_size": 100, "results": [
+			{"id": 12, "email": "carol@example.com", "is_disabled": true, "groups": [{"id": 2, "name": "default"}]}
+		]}`))
+}
+
+func TestLoadCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	directory, err := LoadCSV(strings.NewReader("email,name,groups\nAlice@example.com,Alice,admin; analysts\nbob@example.com,Bob,\n"))
+	assert.Nil(err)
+	assert.Equal(2, len(directory.Users))
+	assert.Equal([]string{"admin", "analysts"}, directory.Users[0].Groups)
+	assert.Nil(directory.Users[1].Groups)
+
+	_, err = LoadCSV(strings.NewReader("email\na@example.com\nA@example.com\n"))
+	assert.NotNil(err)
+}
+
+func TestLoadJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	directory, err := LoadJSON(strings.NewReader(`[{"email": "alice@example.com", "groups": ["admin"]}]`))
+	assert.Nil(err)
+	assert.Equal(1, len(directory.Users))
+
+	directory, err = LoadJSON(strings.NewReader(`{"users": [{"email": "alice@example.com"}, {"email": "bob@example.com"}]}`))
+	assert.Nil(err)
+	assert.Equal(2, len(directory.Users))
+}
+
+func TestPlan(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+	registerState()
+
+	syncer := NewSyncer(c, Options{DisableMissing: true, CreateGroups: true})
+	plan, err := syncer.Plan(&Directory{Users: []DirectoryUser{
+		{Email: "ALICE@example.com", Groups: []string{"admin", "analysts"}},
+		{Email: "carol@example.com", Groups: []string{"finance"}},
+		{Email: "dave@example.com", Name: "Dave", Groups: []string{"analysts"}},
+	}})
+	assert.Nil(err)
+
+	actions := []string{}
+	for _, action := range plan.Actions {
+		actions = append(actions, action.String())
+	}
+	assert.Equal([]string{
+		`create_group "finance"`,
+		`create_user dave@example.com`,
+		`enable_user carol@example.com`,
+		`add_member ALICE@example.com -> "analysts"`,
+		`add_member carol@example.com -> "finance"`,
+		`add_member dave@example.com -> "analysts"`,
+		`disable_user bob@example.com`,
+	}, actions)
+
+	syncer.Options.CreateGroups = false
+	_, err = syncer.Plan(&Directory{Users: []DirectoryUser{{Email: "carol@example.com", Groups: []string{"finance"}}}})
+	assert.NotNil(err)
+}
+
+func TestApply(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/groups",
+		httpmock.NewStringResponder(500, `{"message": "boom"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/users",
+		httpmock.NewStringResponder(200, `{"id": 13, "email": "dave@example.com"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/groups/3/members",
+		httpmock.NewStringResponder(200, `{}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/users/11/disable",
+		httpmock.NewStringResponder(200, `{}`))
+
+	plan := &Plan{Actions: []Action{
+		{Type: CreateGroup, Group: "finance"},
+		{Type: CreateUser, Email: "dave@example.com", Name: "Dave"},
+		{Type: AddMember, Email: "carol@example.com", Group: "finance", UserID: 12},
+		{Type: AddMember, Email: "dave@example.com", Group: "analysts", GroupID: 3},
+		{Type: DisableUser, Email: "bob@example.com", UserID: 11},
+	}}
+
+	dryRun := NewSyncer(c, Options{DryRun: true}).Apply(plan)
+	assert.Nil(dryRun.Err())
+	assert.Equal(5, len(dryRun.Audit))
+	assert.Equal(StatusPlanned, dryRun.Audit[0].Status)
+	assert.Equal(0, httpmock.GetTotalCallCount())
+
+	result := NewSyncer(c, Options{}).Apply(plan)
+	assert.NotNil(result.Err())
+	assert.Equal(StatusFailed, result.Audit[0].Status)
+	assert.Equal(StatusApplied, result.Audit[1].Status)
+	assert.Equal(StatusSkipped, result.Audit[2].Status)
+	assert.Equal(StatusApplied, result.Audit[3].Status)
+	assert.Equal(13, result.Audit[3].Action.UserID)
+	assert.Equal(StatusApplied, result.Audit[4].Status)
+	assert.Equal(2, len(result.Failed()))
+
+	buffer := bytes.Buffer{}
+	assert.Nil(result.WriteAudit(&buffer))
+	assert.Equal(5, strings.Count(buffer.String(), "\n"))
+}
@@ -0,0 +1,85 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// RequestInfo describes an HTTP request made by a Client
+type RequestInfo struct {
+	// Operation is the exported Client method making the request, such as "GetQuery"
+	Operation string
+	Method    string
+	// Path is the API path without the organization prefix, such as "/api/queries/42"
+	Path string
+	// Endpoint is Path with numeric IDs replaced, such as "/api/queries/{id}"
+	Endpoint string
+	// Resource is the first segment after /api/, such as "queries", and ResourceID the ID following it
+	Resource   string
+	ResourceID string
+	Org        string
+	Attempt    int
+}
+
+// Instrumentation observes every HTTP request of a Client, such as the OpenTelemetry
+// implementation of the otelredash module. The returned context is used for the request,
+// end is called with the response status, or the error when there is no response.
+type Instrumentation interface {
+	StartRequest(ctx context.Context, info RequestInfo) (_ context.Context, end func(status int, err error))
+}
+
+var pathID = regexp.MustCompile(`/\d+(/|\.|$)`)
+
+func newRequestInfo(method, path, org string, attempt int) RequestInfo {
+	info := RequestInfo{
+		Operation: callerOperation(),
+		Method:    method,
+		Path:      path,
+		Endpoint:  path,
+		Org:       org,
+		Attempt:   attempt,
+	}
+	for pathID.MatchString(info.Endpoint) {
+		info.Endpoint = pathID.ReplaceAllString(info.Endpoint, "/{id}$1")
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+	info.Resource = segments[0]
+	if len(segments) > 1 && strings.Trim(segments[1], "0123456789") == "" {
+		info.ResourceID = segments[1]
+	}
+	return info
+}
+
+// callerOperation returns the innermost exported Client method on the call stack
+func callerOperation() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if i := strings.LastIndex(frame.Function, ".(*Client)."); i >= 0 {
+			name := frame.Function[i+len(".(*Client)."):]
+			if name != "" && strings.ToUpper(name[:1]) == name[:1] {
+				return name
+			}
+		}
+		if !more {
+			return ""
+		}
+	}
+}
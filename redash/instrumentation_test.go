@@ -0,0 +1,112 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey string
+
+type recordedRequest struct {
+	info   RequestInfo
+	value  interface{}
+	status int
+	err    error
+}
+
+type recordingInstrumentation struct {
+	requests []recordedRequest
+}
+
+func (r *recordingInstrumentation) StartRequest(ctx context.Context, info RequestInfo) (context.Context, func(int, error)) {
+	i := len(r.requests)
+	r.requests = append(r.requests, recordedRequest{info: info, value: ctx.Value(contextKey("trace"))})
+	return ctx, func(status int, err error) {
+		r.requests[i].status = status
+		r.requests[i].err = err
+	}
+}
+
+func TestInstrumentation(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://com.acme/acme-eu/api/queries/42",
+		httpmock.NewStringResponder(200, `{"id": 42, "latest_query_data_id": 3919563}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/acme-eu/api/query_results/3919563",
+		httpmock.NewStringResponder(404, `{"message": "Not found"}`))
+
+	instrumentation := &recordingInstrumentation{}
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy", OrgSlug: "acme-eu", Instrumentation: instrumentation})
+
+	ctx := context.WithValue(context.Background(), contextKey("trace"), "parent")
+	_, err := c.WithContext(ctx).GetLatestQueryResult(42)
+	assert.NotNil(err)
+
+	assert.Equal([]recordedRequest{
+		{
+			info: RequestInfo{
+				Operation:  "GetQuery",
+				Method:     "GET",
+				Path:       "/api/queries/42",
+				Endpoint:   "/api/queries/{id}",
+				Resource:   "queries",
+				ResourceID: "42",
+				Org:        "acme-eu",
+				Attempt:    1,
+			},
+			value:  "parent",
+			status: 200,
+		},
+		{
+			info: RequestInfo{
+				Operation:  "GetQueryResult",
+				Method:     "GET",
+				Path:       "/api/query_results/3919563",
+				Endpoint:   "/api/query_results/{id}",
+				Resource:   "query_results",
+				ResourceID: "3919563",
+				Org:        "acme-eu",
+				Attempt:    1,
+			},
+			value:  "parent",
+			status: 404,
+		},
+	}, instrumentation.requests)
+}
+
+func TestNewRequestInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	info := newRequestInfo("POST", "/api/dashboards/7/share", "", 2)
+	assert.Equal("/api/dashboards/{id}/share", info.Endpoint)
+	assert.Equal("dashboards", info.Resource)
+	assert.Equal("7", info.ResourceID)
+
+	info = newRequestInfo("GET", "/api/queries/1/results/2.json", "", 1)
+	assert.Equal("/api/queries/{id}/results/{id}.json", info.Endpoint)
+
+	info = newRequestInfo("GET", "/api/groups/3/members/4", "", 1)
+	assert.Equal("/api/groups/{id}/members/{id}", info.Endpoint)
+
+	info = newRequestInfo("GET", "/api/jobs/f2c3", "", 1)
+	assert.Equal("jobs", info.Resource)
+	assert.Equal("", info.ResourceID)
+}
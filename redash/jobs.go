@@ -0,0 +1,81 @@
+package redash
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Job statuses reported by Redash
+const (
+	JobStatusPending   = 1
+	JobStatusStarted   = 2
+	JobStatusSuccess   = 3
+	JobStatusFailure   = 4
+	JobStatusCancelled = 5
+)
+
+// Job object structure from Redash's /api/jobs/<ID> endpoint
+type Job struct {
+	ID            string      `json:"id"`
+	Status        int         `json:"status"`
+	Error         string      `json:"error"`
+	QueryResultID int         `json:"query_result_id"`
+	UpdatedAt     interface{} `json:"updated_at"`
+}
+
+type jobResponse struct {
+	Job Job `json:"job"`
+}
+
+// Done returns true once the job has succeeded, failed or been cancelled
+func (j *Job) Done() bool {
+	return j.Status == JobStatusSuccess || j.Status == JobStatusFailure || j.Status == JobStatusCancelled
+}
+
+// GetJob returns the current state of a Redash job
+func (c *Client) GetJob(id string) (*Job, error) {
+	path := "/api/jobs/" + id
+
+	queryParams := url.Values{}
+	response, err := c.get(path, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	result := new(jobResponse)
+	err = json.NewDecoder(response.Body).Decode(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.Job, nil
+}
+
+// WaitForJob polls a job every interval until it is done or the timeout expires.
+// A failed or cancelled job is returned along with an error.
+func (c *Client) WaitForJob(id string, interval, timeout time.Duration) (*Job, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		job, err := c.GetJob(id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch job.Status {
+		case JobStatusSuccess:
+			return job, nil
+		case JobStatusFailure:
+			return job, fmt.Errorf("job %s failed: %s", id, job.Error)
+		case JobStatusCancelled:
+			return job, fmt.Errorf("job %s was cancelled", id)
+		}
+
+		if time.Now().After(deadline) {
+			return job, fmt.Errorf("job %s did not finish within %s", id, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
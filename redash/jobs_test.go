@@ -0,0 +1,47 @@
+package redash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJob(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/f2c3",
+		httpmock.NewStringResponder(200, `{"job": {"id": "f2c3", "status": 2, "error": "", "query_result_id": null}}`))
+
+	job, err := c.GetJob("f2c3")
+	assert.Nil(err)
+	assert.Equal(JobStatusStarted, job.Status)
+	assert.False(job.Done())
+}
+
+func TestWaitForJob(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/f2c3",
+		httpmock.NewStringResponder(200, `{"job": {"id": "f2c3", "status": 1}}`))
+
+	job, err := c.WaitForJob("f2c3", time.Millisecond, 5*time.Millisecond)
+	assert.EqualError(err, "job f2c3 did not finish within 5ms")
+	assert.Equal(JobStatusPending, job.Status)
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/f2c3",
+		httpmock.NewStringResponder(200, `{"job": {"id": "f2c3", "status": 5}}`))
+
+	job, err = c.WaitForJob("f2c3", time.Millisecond, time.Minute)
+	assert.EqualError(err, "job f2c3 was cancelled")
+	assert.True(job.Done())
+}
@@ -0,0 +1,333 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Package layout positions widgets on the 6 column grid of Redash dashboards
+package layout
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// Grid settings used by the Redash dashboard UI
+const (
+	Columns = 6
+
+	DefaultSizeX = 3
+	DefaultSizeY = 3
+	MinSizeX     = 1
+	MaxSizeX     = Columns
+	MinSizeY     = 1
+	MaxSizeY     = 1000
+)
+
+// Size is the width (in columns) and height (in rows) of a widget, zero values use the defaults
+type Size struct {
+	Width  int
+	Height int
+}
+
+// Common widget sizes
+var (
+	Full    = Size{Width: 6, Height: 8}
+	Half    = Size{Width: 3, Height: 8}
+	Third   = Size{Width: 2, Height: 8}
+	Header  = Size{Width: 6, Height: 2}
+	Default = Size{Width: DefaultSizeX, Height: DefaultSizeY}
+)
+
+func (s Size) normalize() Size {
+	if s.Width == 0 {
+		s.Width = DefaultSizeX
+	}
+	if s.Height == 0 {
+		s.Height = DefaultSizeY
+	}
+	return s
+}
+
+// NewPosition returns a WidgetPosition with Redash's default size constraints
+func NewPosition(col, row int, size Size) redash.WidgetPosition {
+	size = size.normalize()
+	return redash.WidgetPosition{
+		SizeX:    size.Width,
+		SizeY:    size.Height,
+		MinSizeX: MinSizeX,
+		MaxSizeX: MaxSizeX,
+		MinSizeY: MinSizeY,
+		MaxSizeY: MaxSizeY,
+		Col:      col,
+		Row:      row,
+	}
+}
+
+// Kinds of Problem
+const (
+	ProblemOverlap     = "overlap"
+	ProblemOutOfBounds = "out_of_bounds"
+	ProblemSize        = "size"
+)
+
+// Problem describes an invalid widget position. Index (and Other for overlaps)
+// refer to the order positions were given to Validate or added to the Layout.
+type Problem struct {
+	Kind  string
+	Index int
+	Other int
+}
+
+func (p Problem) String() string {
+	switch p.Kind {
+	case ProblemOverlap:
+		return fmt.Sprintf("widget %d overlaps widget %d", p.Index, p.Other)
+	case ProblemOutOfBounds:
+		return fmt.Sprintf("widget %d is outside of the %d column grid", p.Index, Columns)
+	default:
+		return fmt.Sprintf("widget %d is outside of its min/max size", p.Index)
+	}
+}
+
+func overlaps(a, b redash.WidgetPosition) bool {
+	return a.Col < b.Col+b.SizeX && b.Col < a.Col+a.SizeX &&
+		a.Row < b.Row+b.SizeY && b.Row < a.Row+a.SizeY
+}
+
+func inBounds(p redash.WidgetPosition) bool {
+	return p.Col >= 0 && p.Row >= 0 && p.SizeX >= 1 && p.SizeY >= 1 && p.Col+p.SizeX <= Columns
+}
+
+func validSize(p redash.WidgetPosition) bool {
+	if p.MinSizeX > 0 && p.SizeX < p.MinSizeX || p.MaxSizeX > 0 && p.SizeX > p.MaxSizeX {
+		return false
+	}
+	if p.MinSizeY > 0 && p.SizeY < p.MinSizeY || p.MaxSizeY > 0 && p.SizeY > p.MaxSizeY {
+		return false
+	}
+	return true
+}
+
+// Validate reports out of bounds, min/max size violations and overlapping widgets
+func Validate(positions []redash.WidgetPosition) []Problem {
+	problems := []Problem{}
+	for i, p := range positions {
+		if !inBounds(p) {
+			problems = append(problems, Problem{Kind: ProblemOutOfBounds, Index: i, Other: -1})
+		}
+		if !validSize(p) {
+			problems = append(problems, Problem{Kind: ProblemSize, Index: i, Other: -1})
+		}
+		for j := i + 1; j < len(positions); j++ {
+			if overlaps(p, positions[j]) {
+				problems = append(problems, Problem{Kind: ProblemOverlap, Index: i, Other: j})
+			}
+		}
+	}
+	return problems
+}
+
+// Compact moves every widget up as far as it can go without overlapping the widgets
+// above it, keeping columns and the top-to-bottom order. Positions are returned in the
+// order they were given.
+func Compact(positions []redash.WidgetPosition) []redash.WidgetPosition {
+	order := make([]int, len(positions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		pa, pb := positions[order[a]], positions[order[b]]
+		if pa.Row != pb.Row {
+			return pa.Row < pb.Row
+		}
+		return pa.Col < pb.Col
+	})
+
+	compacted := make([]redash.WidgetPosition, len(positions))
+	placed := []redash.WidgetPosition{}
+	for _, i := range order {
+		// Moving up one row at a time stops at the first widget in the way, a widget
+		// never jumps over another into a gap above it
+		p := positions[i]
+		for p.Row > 0 {
+			candidate := p
+			candidate.Row--
+			if !fits(placed, candidate) {
+				break
+			}
+			p = candidate
+		}
+		compacted[i] = p
+		placed = append(placed, p)
+	}
+
+	return compacted
+}
+
+func fits(placed []redash.WidgetPosition, p redash.WidgetPosition) bool {
+	for _, other := range placed {
+		if overlaps(p, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// Layout places widgets on a dashboard grid
+type Layout struct {
+	positions []redash.WidgetPosition
+}
+
+// New returns a Layout already holding the given positions
+func New(existing ...redash.WidgetPosition) *Layout {
+	return &Layout{positions: append([]redash.WidgetPosition{}, existing...)}
+}
+
+// FromDashboard returns a Layout holding the positions of the dashboard widgets, in widget order
+func FromDashboard(dashboard *redash.Dashboard) *Layout {
+	l := New()
+	for _, widget := range dashboard.Widgets {
+		l.positions = append(l.positions, widget.Options.Position)
+	}
+	return l
+}
+
+// Positions returns every position of the layout, in the order they were added
+func (l *Layout) Positions() []redash.WidgetPosition {
+	return append([]redash.WidgetPosition{}, l.positions...)
+}
+
+// Bottom returns the first row below every widget
+func (l *Layout) Bottom() int {
+	bottom := 0
+	for _, p := range l.positions {
+		if p.Row+p.SizeY > bottom {
+			bottom = p.Row + p.SizeY
+		}
+	}
+	return bottom
+}
+
+func checkSize(size Size) error {
+	if size.Width < 1 || size.Width > Columns || size.Height < 1 || size.Height > MaxSizeY {
+		return fmt.Errorf("invalid widget size %dx%d", size.Width, size.Height)
+	}
+	return nil
+}
+
+// Place puts a widget in the first free spot scanning rows top to bottom, then columns left to right
+func (l *Layout) Place(size Size) (redash.WidgetPosition, error) {
+	size = size.normalize()
+	if err := checkSize(size); err != nil {
+		return redash.WidgetPosition{}, err
+	}
+
+	for row := 0; ; row++ {
+		for col := 0; col+size.Width <= Columns; col++ {
+			p := NewPosition(col, row, size)
+			if fits(l.positions, p) {
+				l.positions = append(l.positions, p)
+				return p, nil
+			}
+		}
+	}
+}
+
+// PlaceAt puts a widget at an explicit position, failing if it is out of bounds or overlaps another widget
+func (l *Layout) PlaceAt(p redash.WidgetPosition) error {
+	if !inBounds(p) {
+		return fmt.Errorf("position col %d row %d size %dx%d is outside of the %d column grid", p.Col, p.Row, p.SizeX, p.SizeY, Columns)
+	}
+	if !validSize(p) {
+		return fmt.Errorf("position size %dx%d is outside of its min/max size", p.SizeX, p.SizeY)
+	}
+	for i, other := range l.positions {
+		if overlaps(p, other) {
+			return fmt.Errorf("position col %d row %d overlaps widget %d", p.Col, p.Row, i)
+		}
+	}
+
+	l.positions = append(l.positions, p)
+	return nil
+}
+
+// AddRow places widgets left to right on a new row below every existing widget
+func (l *Layout) AddRow(sizes ...Size) ([]redash.WidgetPosition, error) {
+	width := 0
+	for i := range sizes {
+		sizes[i] = sizes[i].normalize()
+		if err := checkSize(sizes[i]); err != nil {
+			return nil, err
+		}
+		width += sizes[i].Width
+	}
+	if width > Columns {
+		return nil, fmt.Errorf("row is %d columns wide, the grid only has %d", width, Columns)
+	}
+
+	row := l.Bottom()
+	col := 0
+	placed := []redash.WidgetPosition{}
+	for _, size := range sizes {
+		p := NewPosition(col, row, size)
+		placed = append(placed, p)
+		col += size.Width
+	}
+
+	l.positions = append(l.positions, placed...)
+	return placed, nil
+}
+
+// Validate reports problems with the positions of the layout
+func (l *Layout) Validate() []Problem {
+	return Validate(l.positions)
+}
+
+// Compact moves every widget of the layout up as far as it can go
+func (l *Layout) Compact() {
+	l.positions = Compact(l.positions)
+}
+
+// Apply saves positions onto the widgets of a dashboard, positions are matched to
+// widgets by index as returned by FromDashboard. Unchanged widgets are not updated.
+func Apply(client *redash.Client, dashboard *redash.Dashboard, positions []redash.WidgetPosition) error {
+	if len(positions) != len(dashboard.Widgets) {
+		return fmt.Errorf("got %d positions for %d widgets", len(positions), len(dashboard.Widgets))
+	}
+
+	for i, widget := range dashboard.Widgets {
+		if widget.Options.Position == positions[i] {
+			continue
+		}
+
+		options := widget.Options
+		options.Position = positions[i]
+		payload := &redash.WidgetUpdatePayload{
+			Text:    widget.Text,
+			Width:   widget.Width,
+			Options: options,
+		}
+		if widget.Visualization.ID != 0 {
+			visualizationID := widget.Visualization.ID
+			payload.VisualizationID = &visualizationID
+		}
+
+		updated, err := client.UpdateWidget(widget.ID, payload)
+		if err != nil {
+			return err
+		}
+		dashboard.Widgets[i].Options = updated.Options
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package layout
+
+import (
+	"testing"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlace(t *testing.T) {
+	assert := assert.New(t)
+
+	l := New()
+	a, err := l.Place(Half)
+	assert.Nil(err)
+	b, err := l.Place(Third)
+	assert.Nil(err)
+	c, err := l.Place(Half)
+	assert.Nil(err)
+	d, err := l.Place(Size{Width: 1, Height: 2})
+	assert.Nil(err)
+
+	assert.Equal([]int{0, 0}, []int{a.Col, a.Row})
+	assert.Equal([]int{3, 0}, []int{b.Col, b.Row})
+	assert.Equal([]int{0, 8}, []int{c.Col, c.Row})
+	assert.Equal([]int{5, 0}, []int{d.Col, d.Row})
+	assert.Equal(MaxSizeY, a.MaxSizeY)
+	assert.Empty(l.Validate())
+
+	_, err = l.Place(Size{Width: 7, Height: 1})
+	assert.NotNil(err)
+}
+
+func TestAddRowAndPlaceAt(t *testing.T) {
+	assert := assert.New(t)
+
+	l := New()
+	header, err := l.AddRow(Header)
+	assert.Nil(err)
+	assert.Equal(0, header[0].Row)
+
+	row, err := l.AddRow(Third, Third, Size{Width: 2, Height: 4})
+	assert.Nil(err)
+	assert.Equal(3, len(row))
+	assert.Equal([]int{2, 2, 2}, []int{row[0].Row, row[1].Row, row[2].Row})
+	assert.Equal([]int{0, 2, 4}, []int{row[0].Col, row[1].Col, row[2].Col})
+	assert.Equal(10, l.Bottom())
+
+	_, err = l.AddRow(Half, Half, Third)
+	assert.NotNil(err)
+
+	assert.NotNil(l.PlaceAt(NewPosition(3, 7, Third)))
+	assert.NotNil(l.PlaceAt(NewPosition(5, 20, Third)))
+	assert.Nil(l.PlaceAt(NewPosition(4, 6, Size{Width: 2, Height: 2})))
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	oversized := NewPosition(0, 30, Size{Width: 2, Height: 2})
+	oversized.MaxSizeY = 1
+
+	problems := Validate([]redash.WidgetPosition{
+		NewPosition(0, 0, Half),
+		NewPosition(2, 4, Half),
+		NewPosition(5, 10, Half),
+		oversized,
+	})
+	assert.Equal([]Problem{
+		{Kind: ProblemOverlap, Index: 0, Other: 1},
+		{Kind: ProblemOutOfBounds, Index: 2, Other: -1},
+		{Kind: ProblemSize, Index: 3, Other: -1},
+	}, problems)
+}
+
+func TestCompact(t *testing.T) {
+	assert := assert.New(t)
+
+	compacted := Compact([]redash.WidgetPosition{
+		NewPosition(0, 20, Half),
+		NewPosition(3, 4, Half),
+		NewPosition(0, 40, Full),
+	})
+	assert.Equal(0, compacted[0].Row)
+	assert.Equal(0, compacted[1].Row)
+	assert.Equal(8, compacted[2].Row)
+	assert.Empty(Validate(compacted))
+
+	// A widget stays below the widget it was under, even with free space higher up
+	compacted = Compact([]redash.WidgetPosition{
+		{Col: 0, Row: 0, SizeX: 3, SizeY: 4},
+		{Col: 0, Row: 4, SizeX: 6, SizeY: 1},
+		{Col: 3, Row: 5, SizeX: 3, SizeY: 1},
+	})
+	assert.Equal(0, compacted[0].Row)
+	assert.Equal(4, compacted[1].Row)
+	assert.Equal(5, compacted[2].Row)
+}
+
+func TestApply(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/widgets/2",
+		httpmock.NewStringResponder(200, `{"id": 2, "options": {"position": {"col": 0, "row": 0, "sizeX": 3, "sizeY": 8}}}`))
+
+	dashboard := &redash.Dashboard{Widgets: []redash.WidgetDashboard{
+		{ID: 1, Options: redash.WidgetOptions{Position: NewPosition(0, 0, Half)}},
+		{ID: 2, Options: redash.WidgetOptions{Position: NewPosition(0, 12, Half)}},
+	}}
+
+	l := FromDashboard(dashboard)
+	l.Compact()
+	err := Apply(c, dashboard, l.Positions())
+	assert.Nil(err)
+	assert.Equal(1, httpmock.GetTotalCallCount())
+	assert.Equal(8, l.Positions()[1].Row)
+
+	assert.NotNil(Apply(c, dashboard, nil))
+}
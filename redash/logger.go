@@ -0,0 +1,146 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogLevel is the severity of a log entry
+type LogLevel int
+
+// Log levels, request and response bodies are only logged at LogLevelTrace
+const (
+	LogLevelTrace LogLevel = iota
+	LogLevelDebug
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// Logger receives the log entries of a Client. Requests are logged at LogLevelDebug with
+// the fields method, path, status, duration and attempt. Nothing is logged when
+// Config.Logger is nil.
+type Logger interface {
+	Enabled(level LogLevel) bool
+	Log(level LogLevel, msg string, fields map[string]interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Enabled(LogLevel) bool                        { return false }
+func (nopLogger) Log(LogLevel, string, map[string]interface{}) {}
+
+func (c *Client) logger() Logger {
+	if c.Config.Logger != nil {
+		return c.Config.Logger
+	}
+	return nopLogger{}
+}
+
+func (c *Client) log(level LogLevel, msg string, fields map[string]interface{}) {
+	logger := c.logger()
+	if logger.Enabled(level) {
+		logger.Log(level, msg, fields)
+	}
+}
+
+type logrusLogger struct {
+	logger logrus.FieldLogger
+	level  func() logrus.Level
+}
+
+// NewLogrusLogger returns a Logger writing to a logrus logger, such as logrus.StandardLogger()
+func NewLogrusLogger(logger *logrus.Logger) Logger {
+	return &logrusLogger{logger: logger, level: logger.GetLevel}
+}
+
+func (l *logrusLogger) Enabled(level LogLevel) bool {
+	return l.level() >= logrusLevel(level)
+}
+
+func (l *logrusLogger) Log(level LogLevel, msg string, fields map[string]interface{}) {
+	l.logger.WithFields(logrus.Fields(fields)).Log(logrusLevel(level), msg)
+}
+
+func logrusLevel(level LogLevel) logrus.Level {
+	switch level {
+	case LogLevelTrace:
+		return logrus.TraceLevel
+	case LogLevelDebug:
+		return logrus.DebugLevel
+	case LogLevelInfo:
+		return logrus.InfoLevel
+	case LogLevelWarn:
+		return logrus.WarnLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
+
+// RedactedValue replaces secrets in logged bodies
+const RedactedValue = "[REDACTED]"
+
+// secretKeys are the fragments of JSON keys whose values are redacted from logged bodies,
+// they cover API keys and the secrets of data source options. Keys are compared lowercased
+// without separators, so "keyfile" matches both "key_file" and "jsonKeyFile".
+var secretKeys = []string{"password", "passwd", "secret", "token", "apikey", "privatekey", "keyfile", "credential", "connectionstring"}
+
+var keySeparators = strings.NewReplacer("_", "", "-", "")
+
+func isSecretKey(key string) bool {
+	key = keySeparators.Replace(strings.ToLower(key))
+	for _, fragment := range secretKeys {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactJSON returns a JSON body with the values of secret keys, such as passwords, tokens and
+// API keys, replaced by RedactedValue. Other bodies are returned as is.
+func RedactJSON(body string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return body
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return body
+	}
+	return string(redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			if isSecretKey(key) && item != nil && item != "" {
+				value[key] = RedactedValue
+			} else {
+				value[key] = redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactValue(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,36 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+//go:build go1.21
+
+package redash
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	assert := assert.New(t)
+
+	output := new(bytes.Buffer)
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	assert.True(logger.Enabled(LogLevelDebug))
+	assert.False(logger.Enabled(LogLevelTrace))
+
+	logger.Log(LogLevelDebug, "Redash request", map[string]interface{}{"path": "/api/queries", "method": "GET", "status": 200})
+	assert.Contains(output.String(), `level=DEBUG msg="Redash request" method=GET path=/api/queries status=200`)
+}
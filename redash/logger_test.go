@@ -0,0 +1,106 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type logEntry struct {
+	level  LogLevel
+	msg    string
+	fields map[string]interface{}
+}
+
+type recordingLogger struct {
+	level   LogLevel
+	entries []logEntry
+}
+
+func (l *recordingLogger) Enabled(level LogLevel) bool {
+	return level >= l.level
+}
+
+func (l *recordingLogger) Log(level LogLevel, msg string, fields map[string]interface{}) {
+	l.entries = append(l.entries, logEntry{level, msg, fields})
+}
+
+func TestLogger(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/data_sources/1",
+		httpmock.NewStringResponder(200, `{"id": 1, "name": "Warehouse", "options": {"host": "db.acme", "password": "hunter2"}}`))
+
+	logger := &recordingLogger{level: LogLevelDebug}
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy", Logger: logger})
+
+	_, err := c.post("/api/data_sources/1", `{"options": {"host": "db.acme", "password": "hunter2"}}`, nil)
+	assert.Nil(err)
+	assert.Len(logger.entries, 1)
+	assert.Equal("Redash request", logger.entries[0].msg)
+	assert.Equal("POST", logger.entries[0].fields["method"])
+	assert.Equal("/api/data_sources/1", logger.entries[0].fields["path"])
+	assert.Equal(200, logger.entries[0].fields["status"])
+	assert.Equal(1, logger.entries[0].fields["attempt"])
+	assert.Contains(logger.entries[0].fields, "duration")
+
+	// Bodies are only logged at trace level, with secrets redacted
+	logger = &recordingLogger{level: LogLevelTrace}
+	c.Config.Logger = logger
+	response, err := c.post("/api/data_sources/1", `{"options": {"host": "db.acme", "password": "hunter2"}}`, nil)
+	assert.Nil(err)
+	assert.Len(logger.entries, 3)
+	assert.Equal(`{"options":{"host":"db.acme","password":"[REDACTED]"}}`, logger.entries[0].fields["body"])
+	assert.Equal(`{"id":1,"name":"Warehouse","options":{"host":"db.acme","password":"[REDACTED]"}}`, logger.entries[2].fields["body"])
+
+	// The response body can still be read
+	b := new(bytes.Buffer)
+	_, _ = b.ReadFrom(response.Body)
+	assert.Contains(b.String(), "hunter2")
+}
+
+func TestLogrusLogger(t *testing.T) {
+	assert := assert.New(t)
+
+	output := new(bytes.Buffer)
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(output)
+	logrusLogger.SetLevel(logrus.DebugLevel)
+
+	logger := NewLogrusLogger(logrusLogger)
+	assert.True(logger.Enabled(LogLevelDebug))
+	assert.False(logger.Enabled(LogLevelTrace))
+
+	logger.Log(LogLevelDebug, "Redash request", map[string]interface{}{"path": "/api/queries"})
+	assert.Contains(output.String(), "Redash request")
+	assert.Contains(output.String(), "path=/api/queries")
+}
+
+func TestRedactJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	// Data source types name their secrets in snake and camel case
+	body := `{"options": {"projectId": "acme", "jsonKeyFile": "eyJrZXki", "connectionString": "Server=db;Password=hunter2", "aws_secret_key": "s3cr3t", "privateKey": "pem"}, "api_key": "ApIkEy"}`
+	redacted := RedactJSON(body)
+	assert.JSONEq(`{"options": {"projectId": "acme", "jsonKeyFile": "[REDACTED]", "connectionString": "[REDACTED]", "aws_secret_key": "[REDACTED]", "privateKey": "[REDACTED]"}, "api_key": "[REDACTED]"}`, redacted)
+
+	assert.Equal("not json", RedactJSON("not json"))
+}
@@ -0,0 +1,206 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Package markdown renders the markdown of Redash text widgets from templates,
+// so section headers and documentation blocks stay consistent across dashboards
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Renderer renders text/template markdown with shared variables and partials.
+// Partials are included with {{ template "name" . }}, variables are read with {{ .name }}.
+type Renderer struct {
+	partials  map[string]string
+	variables map[string]interface{}
+	funcs     template.FuncMap
+}
+
+// rootName names the rendered text among the partials, a partial cannot take it
+const rootName = "<text>"
+
+// NewRenderer returns a *Renderer with the markdown helpers of DefaultFuncs
+func NewRenderer() *Renderer {
+	return &Renderer{
+		partials:  map[string]string{},
+		variables: map[string]interface{}{},
+		funcs:     DefaultFuncs(),
+	}
+}
+
+// Func registers a template function for this renderer only, replacing a helper of the same name
+func (r *Renderer) Func(name string, fn interface{}) *Renderer {
+	r.funcs[name] = fn
+	return r
+}
+
+// Partial registers a named template that can be included from any rendered text
+func (r *Renderer) Partial(name, body string) *Renderer {
+	r.partials[name] = body
+	return r
+}
+
+// LoadPartials registers every *.md file of a directory as a partial named after the file without extension
+func (r *Renderer) LoadPartials(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		body, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		r.Partial(strings.TrimSuffix(filepath.Base(file), ".md"), string(body))
+	}
+
+	return nil
+}
+
+// Set defines a variable shared by every render, per render variables take precedence
+func (r *Renderer) Set(name string, value interface{}) *Renderer {
+	r.variables[name] = value
+	return r
+}
+
+// Render executes text with the shared variables merged with vars.
+// Referencing an undefined variable or partial is an error.
+func (r *Renderer) Render(text string, vars map[string]interface{}) (string, error) {
+	root := template.New(rootName).Funcs(r.funcs).Option("missingkey=error")
+	for name, body := range r.partials {
+		if name == rootName {
+			return "", fmt.Errorf("partial name %s is reserved", rootName)
+		}
+		if _, err := root.New(name).Parse(body); err != nil {
+			return "", fmt.Errorf("partial %s: %s", name, err)
+		}
+	}
+	if _, err := root.Parse(text); err != nil {
+		return "", err
+	}
+
+	data := map[string]interface{}{}
+	for name, value := range r.variables {
+		data[name] = value
+	}
+	for name, value := range vars {
+		data[name] = value
+	}
+
+	buffer := bytes.Buffer{}
+	if err := root.ExecuteTemplate(&buffer, rootName, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(buffer.String()), nil
+}
+
+// DefaultFuncs returns a copy of the markdown helpers every Renderer starts with
+func DefaultFuncs() template.FuncMap {
+	funcs := template.FuncMap{}
+	for name, fn := range defaultFuncs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+var defaultFuncs = template.FuncMap{
+	"heading": Heading,
+	"link":    Link,
+	"bold":    Bold,
+	"italic":  Italic,
+	"code":    Code,
+	"escape":  Escape,
+	"list":    List,
+	"table":   Table,
+}
+
+// Heading returns a markdown heading of the given level (1 to 6)
+func Heading(level int, text string) string {
+	if level < 1 {
+		level = 1
+	}
+	if level > 6 {
+		level = 6
+	}
+	return strings.Repeat("#", level) + " " + text
+}
+
+// Link returns a markdown link
+func Link(text, url string) string {
+	return "[" + Escape(text) + "](" + url + ")"
+}
+
+// Bold returns bold markdown text
+func Bold(text string) string {
+	return "**" + text + "**"
+}
+
+// Italic returns italic markdown text
+func Italic(text string) string {
+	return "*" + text + "*"
+}
+
+// Code returns inline code
+func Code(text string) string {
+	return "`" + text + "`"
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"#", `\#`, "|", `\|`, "<", `\<`, ">", `\>`,
+)
+
+// Escape escapes markdown control characters so text renders literally
+func Escape(text string) string {
+	return escaper.Replace(text)
+}
+
+// List returns a bulleted markdown list
+func List(items ...string) string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, "- "+item)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Table returns a markdown table, rows are given as flat cells and wrapped every len(headers) cells
+func Table(headers []string, cells ...string) string {
+	if len(headers) == 0 {
+		return ""
+	}
+
+	lines := []string{
+		"| " + strings.Join(headers, " | ") + " |",
+		"|" + strings.Repeat(" --- |", len(headers)),
+	}
+	for start := 0; start < len(cells); start += len(headers) {
+		row := make([]string, len(headers))
+		for i := range row {
+			if start+i < len(cells) {
+				row[i] = Escape(cells[start+i])
+			}
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,98 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package markdown
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRenderer().
+		Set("team", "Data Platform").
+		Partial("owner", `{{ italic (printf "Owned by %s" .team) }}`)
+
+	text, err := r.Render(`
+{{ heading 2 .title }}
+
+{{ template "owner" . }}
+
+See {{ link "the runbook" .runbook }}.
+`, map[string]interface{}{"title": "Revenue", "runbook": "https://example.com/runbook"})
+	assert.Nil(err)
+	assert.Equal("## Revenue\n\n*Owned by Data Platform*\n\nSee [the runbook](https://example.com/runbook).", text)
+
+	text, err = r.Render(`{{ template "owner" . }}`, map[string]interface{}{"team": "Finance"})
+	assert.Nil(err)
+	assert.Equal("*Owned by Finance*", text)
+
+	_, err = r.Render(`{{ .missing }}`, nil)
+	assert.NotNil(err)
+
+	_, err = r.Render(`{{ template "missing" . }}`, nil)
+	assert.NotNil(err)
+}
+
+func TestRenderIsolation(t *testing.T) {
+	assert := assert.New(t)
+
+	// A partial named like the text the root used to be named does not replace it
+	r := NewRenderer().Partial("text", "partial")
+	text, err := r.Render(`text and {{ template "text" }}`, nil)
+	assert.Nil(err)
+	assert.Equal("text and partial", text)
+
+	_, err = NewRenderer().Partial(rootName, "partial").Render("text", nil)
+	assert.EqualError(err, "partial name <text> is reserved")
+
+	// Functions are registered per renderer
+	shout := NewRenderer().Func("bold", func(text string) string { return text + "!" })
+	text, err = shout.Render(`{{ bold "hi" }}`, nil)
+	assert.Nil(err)
+	assert.Equal("hi!", text)
+	text, err = NewRenderer().Render(`{{ bold "hi" }}`, nil)
+	assert.Nil(err)
+	assert.Equal("**hi**", text)
+}
+
+func TestLoadPartials(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "partials")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "footer.md"), []byte("_Updated daily_"), 0644))
+
+	r := NewRenderer()
+	assert.Nil(r.LoadPartials(dir))
+
+	text, err := r.Render(`{{ template "footer" }}`, nil)
+	assert.Nil(err)
+	assert.Equal("_Updated daily_", text)
+}
+
+func TestHelpers(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("###### deep", Heading(9, "deep"))
+	assert.Equal(`a\_b\*`, Escape("a_b*"))
+	assert.Equal("- one\n- two", List("one", "two"))
+	assert.Equal("| Metric | Owner |\n| --- | --- |\n| DAU | growth |\n| MAU |  |", Table([]string{"Metric", "Owner"}, "DAU", "growth", "MAU"))
+}
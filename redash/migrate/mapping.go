@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package migrate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+)
+
+// MappingVersion is the version of the mapping file format
+const MappingVersion = 1
+
+// Mapping records the target ID of every source object already copied, by kind
+type Mapping struct {
+	Version        int         `json:"version"`
+	DataSources    map[int]int `json:"data_sources"`
+	Groups         map[int]int `json:"groups"`
+	Users          map[int]int `json:"users"`
+	Queries        map[int]int `json:"queries"`
+	Visualizations map[int]int `json:"visualizations"`
+	Dashboards     map[int]int `json:"dashboards"`
+	Widgets        map[int]int `json:"widgets"`
+}
+
+// NewMapping returns an empty *Mapping
+func NewMapping() *Mapping {
+	mapping := &Mapping{Version: MappingVersion}
+	mapping.init()
+	return mapping
+}
+
+func (m *Mapping) init() {
+	for _, ids := range []*map[int]int{&m.DataSources, &m.Groups, &m.Users, &m.Queries, &m.Visualizations, &m.Dashboards, &m.Widgets} {
+		if *ids == nil {
+			*ids = map[int]int{}
+		}
+	}
+}
+
+// LoadMapping reads a mapping file, a missing file is an empty mapping
+func LoadMapping(path string) (*Mapping, error) {
+	body, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewMapping(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := &Mapping{}
+	if err := json.Unmarshal(body, mapping); err != nil {
+		return nil, err
+	}
+	mapping.init()
+
+	return mapping, nil
+}
+
+// Save writes the mapping file, replacing it atomically
+func (m *Mapping) Save(path string) error {
+	body, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(body, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
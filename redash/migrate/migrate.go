@@ -0,0 +1,635 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Package migrate copies Redash objects between instances. Every foreign reference
+// (data sources, groups, queries, visualizations, dashboards) is remapped to the IDs
+// of the target instance and recorded in a Mapping, so an interrupted migration can
+// be run again and carries on where it stopped.
+package migrate
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// MaskedSecret is what Redash returns in place of secret data source options
+const MaskedSecret = "--------"
+
+// Source is where objects are copied from, a *redash.Client or anything serving the same objects
+type Source interface {
+	GetDataSources() (*[]redash.DataSource, error)
+	GetDataSource(id int) (*redash.DataSource, error)
+	GetGroups() (*[]redash.Group, error)
+	EachUser(options *redash.UserListOptions, fn func(user *redash.UserListItem) error) error
+	GetQuery(id int) (*redash.Query, error)
+	GetDashboard(slug string) (*redash.Dashboard, error)
+}
+
+// Selection is the object graph to copy, dependencies of selected objects are always copied
+type Selection struct {
+	// DataSources copies every data source, not only those used by copied queries
+	DataSources bool
+	// Groups copies every group, copied data sources are shared with them
+	Groups bool
+	// Users copies every active user with their membership of copied groups
+	Users bool
+	// DisabledUsers also copies disabled users, users it creates are disabled afterwards
+	DisabledUsers bool
+	// Queries to copy with their visualizations
+	Queries []int
+	// Dashboards to copy, by slug, with their widgets and the queries they display
+	Dashboards []string
+}
+
+// Options configures a Migrator
+type Options struct {
+	Selection Selection
+
+	// MappingPath, when set, is where the mapping is saved after every copied object
+	MappingPath string
+
+	// DataSourceOptions are merged into the options of copied data sources, by data
+	// source name. Redash never returns secrets so they have to be provided here.
+	DataSourceOptions map[string]map[string]interface{}
+}
+
+// Result summarises a migration
+type Result struct {
+	Created  []string
+	Matched  []string
+	Warnings []string
+}
+
+// Migrator copies objects from a source to a target instance
+type Migrator struct {
+	source  Source
+	target  *redash.Client
+	mapping *Mapping
+	options Options
+	result  *Result
+
+	targetDataSources map[string]int
+	visited           map[int]bool
+	// unresolved are copied queries with dropdowns reading from queries copied after them
+	unresolved []unresolvedQuery
+}
+
+// unresolvedQuery is a copied query whose parameters are patched once every query is copied
+type unresolvedQuery struct {
+	query        *redash.Query
+	targetID     int
+	dataSourceID int
+	schedule     *redash.QuerySchedule
+}
+
+// NewMigrator returns a *Migrator, objects already in mapping are not copied again
+func NewMigrator(source Source, target *redash.Client, mapping *Mapping, options Options) *Migrator {
+	return &Migrator{source: source, target: target, mapping: mapping, options: options}
+}
+
+// Mapping returns the mapping, updated as objects are copied
+func (m *Migrator) Mapping() *Mapping {
+	return m.mapping
+}
+
+// Run copies the selection. On error the mapping holds everything copied so far.
+func (m *Migrator) Run() (*Result, error) {
+	m.result = &Result{}
+	m.visited = map[int]bool{}
+	m.unresolved = nil
+	selection := m.options.Selection
+
+	if selection.Groups {
+		if err := m.migrateGroups(); err != nil {
+			return m.result, err
+		}
+	}
+
+	if selection.DataSources {
+		dataSources, err := m.source.GetDataSources()
+		if err != nil {
+			return m.result, err
+		}
+		for _, dataSource := range *dataSources {
+			if _, err := m.migrateDataSource(dataSource.ID); err != nil {
+				return m.result, err
+			}
+		}
+	}
+
+	if selection.Users {
+		if err := m.migrateUsers(); err != nil {
+			return m.result, err
+		}
+	}
+
+	for _, id := range selection.Queries {
+		if _, err := m.migrateQuery(id); err != nil {
+			return m.result, err
+		}
+	}
+
+	for _, slug := range selection.Dashboards {
+		if err := m.migrateDashboard(slug); err != nil {
+			return m.result, err
+		}
+	}
+
+	return m.result, m.resolveParameters()
+}
+
+// record adds a copied object to the mapping and saves it
+func (m *Migrator) record(ids map[int]int, kind string, sourceID, targetID int, created bool) error {
+	ids[sourceID] = targetID
+	if created {
+		m.result.Created = append(m.result.Created, fmt.Sprintf("%s %d -> %d", kind, sourceID, targetID))
+	} else {
+		m.result.Matched = append(m.result.Matched, fmt.Sprintf("%s %d -> %d", kind, sourceID, targetID))
+	}
+
+	if m.options.MappingPath == "" {
+		return nil
+	}
+	return m.mapping.Save(m.options.MappingPath)
+}
+
+func (m *Migrator) warn(format string, args ...interface{}) {
+	m.result.Warnings = append(m.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// migrateGroups matches groups by name and creates the missing ones
+func (m *Migrator) migrateGroups() error {
+	groups, err := m.source.GetGroups()
+	if err != nil {
+		return err
+	}
+	targetGroups, err := m.target.GetGroups()
+	if err != nil {
+		return err
+	}
+	existing := map[string]int{}
+	for _, group := range *targetGroups {
+		existing[group.Name] = group.ID
+	}
+
+	for _, group := range *groups {
+		if _, ok := m.mapping.Groups[group.ID]; ok {
+			continue
+		}
+
+		if id, ok := existing[group.Name]; ok {
+			if err := m.record(m.mapping.Groups, "group", group.ID, id, false); err != nil {
+				return err
+			}
+			continue
+		}
+
+		created, err := m.target.CreateGroup(&redash.GroupCreatePayload{Name: group.Name})
+		if err != nil {
+			return err
+		}
+		if err := m.record(m.mapping.Groups, "group", group.ID, created.ID, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateDataSource matches a data source by name or creates it, sharing it with copied groups
+func (m *Migrator) migrateDataSource(id int) (int, error) {
+	if targetID, ok := m.mapping.DataSources[id]; ok {
+		return targetID, nil
+	}
+
+	if m.targetDataSources == nil {
+		dataSources, err := m.target.GetDataSources()
+		if err != nil {
+			return 0, err
+		}
+		m.targetDataSources = map[string]int{}
+		for _, dataSource := range *dataSources {
+			m.targetDataSources[dataSource.Name] = dataSource.ID
+		}
+	}
+
+	dataSource, err := m.source.GetDataSource(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if targetID, ok := m.targetDataSources[dataSource.Name]; ok {
+		return targetID, m.record(m.mapping.DataSources, "data source", id, targetID, false)
+	}
+
+	options := map[string]interface{}{}
+	for name, value := range dataSource.Options {
+		if value == MaskedSecret {
+			if _, ok := m.options.DataSourceOptions[dataSource.Name][name]; !ok {
+				m.warn("data source %q is created without its secret option %s", dataSource.Name, name)
+			}
+			continue
+		}
+		// JSON numbers decode as float64, which the option validation rejects
+		if number, ok := value.(float64); ok && number == math.Trunc(number) {
+			value = int(number)
+		}
+		options[name] = value
+	}
+	for name, value := range m.options.DataSourceOptions[dataSource.Name] {
+		options[name] = value
+	}
+
+	created, err := m.target.CreateDataSource(&redash.DataSource{
+		Name:    dataSource.Name,
+		Type:    dataSource.Type,
+		Options: options,
+	})
+	if err != nil {
+		return 0, err
+	}
+	m.targetDataSources[created.Name] = created.ID
+	if err := m.record(m.mapping.DataSources, "data source", id, created.ID, true); err != nil {
+		return 0, err
+	}
+
+	for groupID := range dataSource.Groups {
+		targetGroupID, ok := m.mapping.Groups[groupID]
+		if !ok {
+			continue
+		}
+		if err := m.target.GroupAddDataSource(targetGroupID, created.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	return created.ID, nil
+}
+
+// migrateUsers matches users by email, disabled ones included, and invites the missing ones.
+// Matched and invited users are added to the copied groups of their source user.
+func (m *Migrator) migrateUsers() error {
+	disabled := true
+	existing := map[string]redash.UserListItem{}
+	for _, options := range []*redash.UserListOptions{{}, {Disabled: &disabled}} {
+		err := m.target.EachUser(options, func(user *redash.UserListItem) error {
+			existing[user.Email] = *user
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	sourceOptions := []*redash.UserListOptions{{}}
+	if m.options.Selection.DisabledUsers {
+		sourceOptions = append(sourceOptions, &redash.UserListOptions{Disabled: &disabled})
+	}
+	for _, options := range sourceOptions {
+		err := m.source.EachUser(options, func(user *redash.UserListItem) error {
+			return m.migrateUser(user, existing)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) migrateUser(user *redash.UserListItem, existing map[string]redash.UserListItem) error {
+	if _, ok := m.mapping.Users[user.ID]; ok {
+		return nil
+	}
+
+	target, matched := existing[user.Email]
+	if matched {
+		if target.IsDisabled && !user.IsDisabled {
+			m.warn("user %s is disabled on the target", user.Email)
+		}
+		if err := m.record(m.mapping.Users, "user", user.ID, target.ID, false); err != nil {
+			return err
+		}
+	} else {
+		created, err := m.target.CreateUser(&redash.UserCreatePayload{Name: user.Name, Email: user.Email})
+		if err != nil {
+			return err
+		}
+		if err := m.record(m.mapping.Users, "user", user.ID, created.ID, true); err != nil {
+			return err
+		}
+		target = redash.UserListItem{ID: created.ID}
+	}
+
+	// Every user is a member of the default group already
+	for _, group := range user.Groups {
+		targetGroupID, ok := m.mapping.Groups[group.ID]
+		if !ok || group.Name == "default" || target.InGroup([]int{targetGroupID}, nil) {
+			continue
+		}
+		if err := m.target.GroupAddUser(targetGroupID, target.ID); err != nil {
+			return err
+		}
+	}
+
+	// Redash creates active users, matched users are left as they are
+	if user.IsDisabled && !matched {
+		return m.target.DisableUser(target.ID)
+	}
+	return nil
+}
+
+// migrateQuery copies a query, the queries its parameters depend on and its visualizations
+func (m *Migrator) migrateQuery(id int) (int, error) {
+	if m.visited[id] {
+		return m.mapping.Queries[id], nil
+	}
+	m.visited[id] = true
+
+	query, err := m.source.GetQuery(id)
+	if err != nil {
+		return 0, err
+	}
+
+	targetID, ok := m.mapping.Queries[id]
+	if !ok {
+		targetID, err = m.createQuery(query)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return targetID, m.migrateVisualizations(query, targetID)
+}
+
+func (m *Migrator) createQuery(query *redash.Query) (int, error) {
+	dataSourceID, err := m.migrateDataSource(query.DataSourceID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Parameters point back to their query through ParentQueryId, which can only be set
+	// once the copy exists, and query-based dropdowns read their values from QueryID
+	selfReferencing := false
+	for _, parameter := range query.Options.Parameters {
+		if parameter.ParentQueryId != 0 || parameter.QueryID == query.ID {
+			selfReferencing = true
+		}
+		if parameter.QueryID != 0 && parameter.QueryID != query.ID {
+			if _, err := m.migrateQuery(parameter.QueryID); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var schedule *redash.QuerySchedule
+	if query.Schedule.Interval != 0 || query.Schedule.Time != "" || query.Schedule.DayOfWeek != "" || query.Schedule.Until != nil {
+		schedule = &query.Schedule
+	}
+
+	created, err := m.target.CreateQuery(&redash.QueryCreatePayload{
+		Name:         query.Name,
+		Description:  query.Description,
+		DataSourceID: dataSourceID,
+		Query:        query.Query,
+		Options:      m.remapParameters(query.Options, query.ID, 0),
+		IsDraft:      query.IsDraft,
+		Tags:         query.Tags,
+		Schedule:     schedule,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := m.record(m.mapping.Queries, "query", query.ID, created.ID, true); err != nil {
+		return 0, err
+	}
+
+	// Dropdowns reading from a query that is being copied, through a cycle, are patched by resolveParameters
+	for _, parameter := range query.Options.Parameters {
+		if parameter.QueryID != 0 && parameter.QueryID != query.ID && m.mapping.Queries[parameter.QueryID] == 0 {
+			m.unresolved = append(m.unresolved, unresolvedQuery{query: query, targetID: created.ID, dataSourceID: dataSourceID, schedule: schedule})
+			return created.ID, nil
+		}
+	}
+
+	// The new ID of the query is only known once it exists
+	if selfReferencing {
+		_, err := m.target.UpdateQuery(created.ID, &redash.QueryUpdatePayload{
+			Name:         query.Name,
+			Description:  query.Description,
+			DataSourceID: dataSourceID,
+			Query:        query.Query,
+			Options:      m.remapParameters(query.Options, query.ID, created.ID),
+			IsDraft:      query.IsDraft,
+			Version:      created.Version,
+			Tags:         query.Tags,
+			Schedule:     schedule,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return created.ID, nil
+}
+
+// resolveParameters updates the queries whose dropdowns read from queries copied after them
+func (m *Migrator) resolveParameters() error {
+	for _, unresolved := range m.unresolved {
+		query := unresolved.query
+		for _, parameter := range query.Options.Parameters {
+			if parameter.QueryID != 0 && parameter.QueryID != query.ID && m.mapping.Queries[parameter.QueryID] == 0 {
+				m.warn("parameter %s of query %d reads from query %d, which was not copied", parameter.Name, query.ID, parameter.QueryID)
+			}
+		}
+
+		target, err := m.target.GetQuery(unresolved.targetID)
+		if err != nil {
+			return err
+		}
+		_, err = m.target.UpdateQuery(unresolved.targetID, &redash.QueryUpdatePayload{
+			Name:         query.Name,
+			Description:  query.Description,
+			DataSourceID: unresolved.dataSourceID,
+			Query:        query.Query,
+			Options:      m.remapParameters(query.Options, query.ID, unresolved.targetID),
+			IsDraft:      query.IsDraft,
+			Version:      target.Version,
+			Tags:         query.Tags,
+			Schedule:     unresolved.schedule,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	m.unresolved = nil
+	return nil
+}
+
+// remapParameters rewrites the query IDs of parameters, sourceID becomes targetID and
+// references to an uncopied query are dropped
+func (m *Migrator) remapParameters(options redash.QueryOptions, sourceID, targetID int) redash.QueryOptions {
+	remapped := redash.QueryOptions{}
+	for _, parameter := range options.Parameters {
+		parameter.ParentQueryId = m.remapQueryID(parameter.ParentQueryId, sourceID, targetID)
+		parameter.QueryID = m.remapQueryID(parameter.QueryID, sourceID, targetID)
+		remapped.Parameters = append(remapped.Parameters, parameter)
+	}
+	return remapped
+}
+
+func (m *Migrator) remapQueryID(id, sourceID, targetID int) int {
+	switch id {
+	case 0:
+		return 0
+	case sourceID:
+		return targetID
+	default:
+		return m.mapping.Queries[id]
+	}
+}
+
+// migrateVisualizations copies the visualizations of a query. Redash creates a table
+// for every new query, an uncopied visualization with the same type and name is reused.
+func (m *Migrator) migrateVisualizations(query *redash.Query, targetQueryID int) error {
+	pending := []redash.VisualizationQuery{}
+	for _, visualization := range query.Visualizations {
+		if _, ok := m.mapping.Visualizations[visualization.ID]; !ok {
+			pending = append(pending, visualization)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	targetQuery, err := m.target.GetQuery(targetQueryID)
+	if err != nil {
+		return err
+	}
+	taken := map[int]bool{}
+	for _, id := range m.mapping.Visualizations {
+		taken[id] = true
+	}
+
+	for _, visualization := range pending {
+		reused := 0
+		for _, candidate := range targetQuery.Visualizations {
+			if !taken[candidate.ID] && candidate.Type == visualization.Type && candidate.Name == visualization.Name {
+				reused = candidate.ID
+				break
+			}
+		}
+
+		if reused != 0 {
+			_, err := m.target.UpdateVisualization(reused, &redash.VisualizationUpdatePayload{
+				Name:        visualization.Name,
+				Description: visualization.Description,
+				Type:        visualization.Type,
+				Options:     visualization.Options,
+			})
+			if err != nil {
+				return err
+			}
+			taken[reused] = true
+			if err := m.record(m.mapping.Visualizations, "visualization", visualization.ID, reused, false); err != nil {
+				return err
+			}
+			continue
+		}
+
+		created, err := m.target.CreateVisualization(&redash.VisualizationCreatePayload{
+			Name:        visualization.Name,
+			Description: visualization.Description,
+			Type:        visualization.Type,
+			Options:     visualization.Options,
+			QueryId:     targetQueryID,
+		})
+		if err != nil {
+			return err
+		}
+		taken[created.ID] = true
+		if err := m.record(m.mapping.Visualizations, "visualization", visualization.ID, created.ID, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateDashboard copies a dashboard, its widgets and everything they display
+func (m *Migrator) migrateDashboard(slug string) error {
+	dashboard, err := m.source.GetDashboard(slug)
+	if err != nil {
+		return err
+	}
+
+	dashboardID, ok := m.mapping.Dashboards[dashboard.ID]
+	if !ok {
+		created, err := m.target.CreateDashboard(&redash.DashboardCreatePayload{Name: dashboard.Name})
+		if err != nil {
+			return err
+		}
+		// Recorded before the update so a failed run does not create the dashboard twice
+		dashboardID = created.ID
+		if err := m.record(m.mapping.Dashboards, "dashboard", dashboard.ID, dashboardID, true); err != nil {
+			return err
+		}
+		_, err = m.target.UpdateDashboard(created.ID, &redash.DashboardUpdatePayload{
+			Name:                    dashboard.Name,
+			Slug:                    created.Slug,
+			IsDraft:                 dashboard.IsDraft,
+			DashboardFiltersEnabled: dashboard.DashboardFiltersEnabled,
+			Tags:                    dashboard.Tags,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, widget := range dashboard.Widgets {
+		if _, ok := m.mapping.Widgets[widget.ID]; ok {
+			continue
+		}
+
+		var visualizationID *int
+		if !widget.IsText() {
+			if _, err := m.migrateQuery(widget.Visualization.Query.ID); err != nil {
+				return err
+			}
+			id, ok := m.mapping.Visualizations[widget.Visualization.ID]
+			if !ok {
+				return fmt.Errorf("visualization %d of widget %d was not copied", widget.Visualization.ID, widget.ID)
+			}
+			visualizationID = &id
+		}
+
+		created, err := m.target.CreateWidget(&redash.WidgetCreatePayload{
+			DashboardID:     dashboardID,
+			Text:            widget.Text,
+			Width:           widget.Width,
+			VisualizationID: visualizationID,
+			Options:         widget.Options,
+		})
+		if err != nil {
+			return err
+		}
+		if err := m.record(m.mapping.Widgets, "widget", widget.ID, created.ID, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/AlmirKadric/redash-client-go/redash/redashtest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// capture records request bodies and answers with the next ID of a sequence
+func capture(bodies *[]map[string]interface{}, ids ...int) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		body := map[string]interface{}{}
+		data, _ := ioutil.ReadAll(req.Body)
+		_ = json.Unmarshal(data, &body)
+		*bodies = append(*bodies, body)
+
+		id := ids[0]
+		if len(ids) > 1 {
+			ids = ids[1:]
+		}
+		return httpmock.NewStringResponse(200, fmt.Sprintf(`{"id": %d, "slug": "sales", "name": "Warehouse"}`, id)), nil
+	}
+}
+
+func registerSource() {
+	httpmock.RegisterResponder("GET", "https://source.acme/api/groups",
+		httpmock.NewStringResponder(200, `[{"id": 1, "name": "admin"}, {"id": 2, "name": "default"}, {"id": 5, "name": "analysts"}]`))
+	httpmock.RegisterResponder("GET", "https://source.acme/api/dashboards/sales",
+		httpmock.NewStringResponder(200, `{
+			"id": 7, "name": "Sales", "slug": "sales", "tags": ["finance"],
+			"widgets": [
+				{"id": 70, "text": "# Sales", "width": 1, "visualization": {}, "options": {"position": {"col": 0, "row": 0, "sizeX": 6, "sizeY": 2}}},
+				{"id": 71, "text": "", "width": 1, "visualization": {"id": 90, "query": {"id": 50}}, "options": {"position": {"col": 0, "row": 2, "sizeX": 3, "sizeY": 8}}}
+			]
+		}`))
+	httpmock.RegisterResponder("GET", "https://source.acme/api/queries/50",
+		httpmock.NewStringResponder(200, `{
+			"id": 50, "name": "Revenue", "data_source_id": 3, "query": "select 1",
+			"options": {"parameters": [{"name": "day", "parentQueryId": 50}, {"name": "region", "type": "query", "parentQueryId": 50, "queryId": 51}]},
+			"visualizations": [{"id": 89, "name": "Table", "type": "TABLE"}, {"id": 90, "name": "Chart", "type": "CHART", "options": {"globalSeriesType": "line"}}]
+		}`))
+	httpmock.RegisterResponder("GET", "https://source.acme/api/queries/51",
+		httpmock.NewStringResponder(200, `{
+			"id": 51, "name": "Regions", "data_source_id": 3, "query": "select region",
+			"visualizations": [{"id": 91, "name": "Table", "type": "TABLE"}]
+		}`))
+	httpmock.RegisterResponder("GET", "https://source.acme/api/data_sources/3",
+		httpmock.NewStringResponder(200, `{
+			"id": 3, "name": "Warehouse", "type": "pg",
+			"options": {"host": "db", "port": 5432, "password": "--------"},
+			"groups": {"5": false}
+		}`))
+}
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	source, _ := redash.NewClient(&redash.Config{RedashURI: "https://source.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+	target, _ := redash.NewClient(&redash.Config{RedashURI: "https://target.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+	registerSource()
+
+	httpmock.RegisterResponder("GET", "https://target.acme/api/groups",
+		httpmock.NewStringResponder(200, `[{"id": 11, "name": "admin"}, {"id": 12, "name": "default"}]`))
+	httpmock.RegisterResponder("GET", "https://target.acme/api/data_sources",
+		httpmock.NewStringResponder(200, `[]`))
+	httpmock.RegisterResponder("GET", "https://target.acme/api/data_sources/types",
+		httpmock.NewStringResponder(200, `[{"type": "pg", "configuration_schema": {
+			"properties": {"host": {"type": "string"}, "port": {"type": "number"}, "password": {"type": "string"}},
+			"secret": ["password"]
+		}}]`))
+	httpmock.RegisterResponder("GET", "https://target.acme/api/queries/501",
+		httpmock.NewStringResponder(200, `{"id": 501, "visualizations": [{"id": 900, "name": "Table", "type": "TABLE"}]}`))
+	httpmock.RegisterResponder("GET", "https://target.acme/api/queries/502",
+		httpmock.NewStringResponder(200, `{"id": 502, "visualizations": [{"id": 901, "name": "Table", "type": "TABLE"}]}`))
+
+	var groups, dataSources, access, queries, queryUpdates, updates, visualizations, dashboards, widgets []map[string]interface{}
+	httpmock.RegisterResponder("POST", "https://target.acme/api/groups", capture(&groups, 15))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/data_sources", capture(&dataSources, 30))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/groups/15/data_sources", capture(&access, 30))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/queries", capture(&queries, 501, 502))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/queries/502", capture(&queryUpdates, 502))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/visualizations/900", capture(&updates, 900))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/visualizations/901", capture(&updates, 901))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/visualizations", capture(&visualizations, 902))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/dashboards", capture(&dashboards, 700))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/dashboards/700", capture(&dashboards, 700))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/widgets", capture(&widgets, 7000, 7001))
+
+	path := filepath.Join(t.TempDir(), "mapping.json")
+	migrator := NewMigrator(source, target, NewMapping(), Options{
+		Selection:         Selection{Groups: true, Dashboards: []string{"sales"}},
+		MappingPath:       path,
+		DataSourceOptions: map[string]map[string]interface{}{"Warehouse": {"password": "s3cret"}},
+	})
+
+	result, err := migrator.Run()
+	assert.Nil(err)
+	assert.Equal(0, len(result.Warnings))
+	assert.Equal([]string{"group 1 -> 11", "group 2 -> 12", "visualization 91 -> 900", "visualization 89 -> 901"}, result.Matched)
+
+	mapping := migrator.Mapping()
+	assert.Equal(map[int]int{1: 11, 2: 12, 5: 15}, mapping.Groups)
+	assert.Equal(map[int]int{3: 30}, mapping.DataSources)
+	assert.Equal(map[int]int{51: 501, 50: 502}, mapping.Queries)
+	assert.Equal(map[int]int{89: 901, 90: 902, 91: 900}, mapping.Visualizations)
+	assert.Equal(map[int]int{7: 700}, mapping.Dashboards)
+	assert.Equal(map[int]int{70: 7000, 71: 7001}, mapping.Widgets)
+
+	// Secrets come from the options, numbers stay numbers
+	assert.Equal(map[string]interface{}{"host": "db", "port": float64(5432), "password": "s3cret"}, dataSources[0]["options"])
+	assert.Equal(float64(30), access[0]["data_source_id"])
+
+	// The dropdown's query is copied first, the parameters point back to their query once its ID is known
+	assert.Equal("Regions", queries[0]["name"])
+	assert.Equal(float64(30), queries[0]["data_source_id"])
+	parameters := queries[1]["options"].(map[string]interface{})["parameters"].([]interface{})
+	assert.Equal(float64(0), parameters[0].(map[string]interface{})["parentQueryId"])
+	assert.Equal(float64(0), parameters[1].(map[string]interface{})["parentQueryId"])
+	assert.Equal(float64(501), parameters[1].(map[string]interface{})["queryId"])
+	parameters = queryUpdates[0]["options"].(map[string]interface{})["parameters"].([]interface{})
+	assert.Equal(float64(502), parameters[0].(map[string]interface{})["parentQueryId"])
+	assert.Equal(float64(502), parameters[1].(map[string]interface{})["parentQueryId"])
+	assert.Equal(float64(501), parameters[1].(map[string]interface{})["queryId"])
+
+	assert.Equal(float64(502), visualizations[0]["query_id"])
+	assert.Equal([]interface{}{"finance"}, dashboards[1]["tags"])
+	assert.Equal(nil, widgets[0]["visualization_id"])
+	assert.Equal(float64(700), widgets[1]["dashboard_id"])
+	assert.Equal(float64(902), widgets[1]["visualization_id"])
+
+	saved, err := LoadMapping(path)
+	assert.Nil(err)
+	assert.Equal(mapping, saved)
+
+	// A second run finds everything mapped and copies nothing
+	httpmock.ZeroCallCounters()
+	result, err = NewMigrator(source, target, saved, Options{Selection: Selection{Groups: true, Dashboards: []string{"sales"}}}).Run()
+	assert.Nil(err)
+	assert.Equal(0, len(result.Created)+len(result.Matched))
+	for key, count := range httpmock.GetCallCountInfo() {
+		if count > 0 {
+			assert.Equal("GET", key[:3], key)
+		}
+	}
+}
+
+func TestRunWarnsAboutSecrets(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	source, _ := redash.NewClient(&redash.Config{RedashURI: "https://source.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+	target, _ := redash.NewClient(&redash.Config{RedashURI: "https://target.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+	registerSource()
+
+	httpmock.RegisterResponder("GET", "https://source.acme/api/data_sources",
+		httpmock.NewStringResponder(200, `[{"id": 3, "name": "Warehouse"}]`))
+	httpmock.RegisterResponder("GET", "https://target.acme/api/data_sources",
+		httpmock.NewStringResponder(200, `[]`))
+	httpmock.RegisterResponder("GET", "https://target.acme/api/data_sources/types",
+		httpmock.NewStringResponder(200, `[]`))
+	var dataSources []map[string]interface{}
+	httpmock.RegisterResponder("POST", "https://target.acme/api/data_sources", capture(&dataSources, 30))
+
+	result, err := NewMigrator(source, target, NewMapping(), Options{Selection: Selection{DataSources: true}}).Run()
+	assert.Nil(err)
+	assert.Equal([]string{`data source "Warehouse" is created without its secret option password`}, result.Warnings)
+	assert.Equal(map[string]interface{}{"host": "db", "port": float64(5432)}, dataSources[0]["options"])
+}
+
+func TestRunRecordsDashboardBeforeUpdate(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	source, _ := redash.NewClient(&redash.Config{RedashURI: "https://source.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+	target, _ := redash.NewClient(&redash.Config{RedashURI: "https://target.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+	registerSource()
+
+	var dashboards []map[string]interface{}
+	httpmock.RegisterResponder("POST", "https://target.acme/api/dashboards", capture(&dashboards, 700))
+	httpmock.RegisterResponder("POST", "https://target.acme/api/dashboards/700",
+		httpmock.NewStringResponder(500, `{"message": "Internal Server Error"}`))
+
+	// A rerun reuses the dashboard created by the failed run instead of creating another one
+	path := filepath.Join(t.TempDir(), "mapping.json")
+	_, err := NewMigrator(source, target, NewMapping(), Options{Selection: Selection{Dashboards: []string{"sales"}}, MappingPath: path}).Run()
+	assert.NotNil(err)
+	assert.Len(dashboards, 1)
+
+	saved, err := LoadMapping(path)
+	assert.Nil(err)
+	assert.Equal(map[int]int{7: 700}, saved.Dashboards)
+}
+
+func TestRunResolvesDropdownCycles(t *testing.T) {
+	assert := assert.New(t)
+
+	sourceServer, targetServer := redashtest.NewServer(), redashtest.NewServer()
+	defer sourceServer.Close()
+	defer targetServer.Close()
+	source, target := sourceServer.Client(), targetServer.Client()
+
+	dataSource, err := source.CreateDataSource(&redash.DataSource{Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"dbname": "dwh"}})
+	assert.Nil(err)
+	_, err = target.CreateDataSource(&redash.DataSource{Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"dbname": "dwh"}})
+	assert.Nil(err)
+
+	// Regions and countries each offer a dropdown of the other
+	regions, err := source.CreateQuery(&redash.QueryCreatePayload{Name: "Regions", DataSourceID: dataSource.ID, Query: "select region"})
+	assert.Nil(err)
+	countries, err := source.CreateQuery(&redash.QueryCreatePayload{Name: "Countries", DataSourceID: dataSource.ID, Query: "select country",
+		Options: redash.QueryOptions{Parameters: []redash.QueryOptionsParameter{{Name: "region", Type: "query", QueryID: regions.ID}}}})
+	assert.Nil(err)
+	_, err = source.UpdateQuery(regions.ID, &redash.QueryUpdatePayload{Name: "Regions", DataSourceID: dataSource.ID, Query: "select region",
+		Options: redash.QueryOptions{Parameters: []redash.QueryOptionsParameter{{Name: "country", Type: "query", QueryID: countries.ID}}}})
+	assert.Nil(err)
+
+	migrator := NewMigrator(source, target, NewMapping(), Options{Selection: Selection{Queries: []int{regions.ID}}})
+	result, err := migrator.Run()
+	assert.Nil(err)
+	assert.Empty(result.Warnings)
+
+	mapping := migrator.Mapping()
+	for sourceID, parameterSourceID := range map[int]int{regions.ID: countries.ID, countries.ID: regions.ID} {
+		query, err := target.GetQuery(mapping.Queries[sourceID])
+		assert.Nil(err)
+		assert.Equal(mapping.Queries[parameterSourceID], query.Options.Parameters[0].QueryID)
+	}
+}
+
+func TestRunMatchesDisabledUsers(t *testing.T) {
+	assert := assert.New(t)
+
+	sourceServer, targetServer := redashtest.NewServer(), redashtest.NewServer()
+	defer sourceServer.Close()
+	defer targetServer.Close()
+	source, target := sourceServer.Client(), targetServer.Client()
+
+	analysts, err := source.CreateGroup(&redash.GroupCreatePayload{Name: "analysts"})
+	assert.Nil(err)
+	for _, email := range []string{"ada@example.com", "grace@example.com"} {
+		user, err := source.CreateUser(&redash.UserCreatePayload{Name: email, Email: email})
+		assert.Nil(err)
+		assert.Nil(source.GroupAddUser(analysts.ID, user.ID))
+	}
+
+	// Ada exists on the target, disabled, Grace exists and is active
+	ada, err := target.CreateUser(&redash.UserCreatePayload{Name: "Ada", Email: "ada@example.com"})
+	assert.Nil(err)
+	assert.Nil(target.DisableUser(ada.ID))
+	grace, err := target.CreateUser(&redash.UserCreatePayload{Name: "Grace", Email: "grace@example.com"})
+	assert.Nil(err)
+
+	migrator := NewMigrator(source, target, NewMapping(), Options{Selection: Selection{Groups: true, Users: true}})
+	result, err := migrator.Run()
+	assert.Nil(err)
+	assert.Equal([]string{"user ada@example.com is disabled on the target"}, result.Warnings)
+
+	targetAnalysts := migrator.Mapping().Groups[analysts.ID]
+	for _, id := range []int{ada.ID, grace.ID} {
+		user, err := target.GetUser(id)
+		assert.Nil(err)
+		assert.Contains(user.Groups, targetAnalysts)
+	}
+}
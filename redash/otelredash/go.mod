@@ -0,0 +1,29 @@
+module github.com/AlmirKadric/redash-client-go/redash/otelredash
+
+go 1.21
+
+require (
+	github.com/AlmirKadric/redash-client-go v0.0.0
+	github.com/jarcoal/httpmock v1.0.8
+	github.com/stretchr/testify v1.9.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/metric v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/sdk/metric v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/sirupsen/logrus v1.9.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// The Instrumentation API is not in a tagged release of the client yet, require that
+// release and drop the replace once it is published
+replace github.com/AlmirKadric/redash-client-go => ../..
@@ -0,0 +1,149 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Package otelredash instruments a redash.Client with OpenTelemetry, one span per HTTP
+// request and request counters and latency histograms by endpoint and status:
+//
+//	instrumentation, err := otelredash.New(otelredash.Options{})
+//	client, err := redash.NewClient(&redash.Config{..., Instrumentation: instrumentation})
+package otelredash
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer and meter
+const ScopeName = "github.com/AlmirKadric/redash-client-go/redash/otelredash"
+
+// Attribute keys, the HTTP ones follow the OpenTelemetry semantic conventions
+const (
+	OperationKey  = attribute.Key("redash.operation")
+	ResourceKey   = attribute.Key("redash.resource")
+	ResourceIDKey = attribute.Key("redash.resource_id")
+	OrgKey        = attribute.Key("redash.org")
+	EndpointKey   = attribute.Key("redash.endpoint")
+	MethodKey     = attribute.Key("http.request.method")
+	StatusKey     = attribute.Key("http.response.status_code")
+	ResendKey     = attribute.Key("http.request.resend_count")
+	ErrorTypeKey  = attribute.Key("error.type")
+)
+
+// Options of the instrumentation, the global providers are used when they are nil
+type Options struct {
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+}
+
+// Instrumentation implements redash.Instrumentation
+type Instrumentation struct {
+	tracer   trace.Tracer
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// New returns the instrumentation to set as redash.Config.Instrumentation
+func New(options Options) (*Instrumentation, error) {
+	if options.TracerProvider == nil {
+		options.TracerProvider = otel.GetTracerProvider()
+	}
+	if options.MeterProvider == nil {
+		options.MeterProvider = otel.GetMeterProvider()
+	}
+
+	meter := options.MeterProvider.Meter(ScopeName)
+	requests, err := meter.Int64Counter("redash.client.requests",
+		metric.WithDescription("Redash API requests"), metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, err
+	}
+	errors, err := meter.Int64Counter("redash.client.errors",
+		metric.WithDescription("Redash API requests failing or answered with an error status"), metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("redash.client.request.duration",
+		metric.WithDescription("Duration of Redash API requests"), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Instrumentation{
+		tracer:   options.TracerProvider.Tracer(ScopeName),
+		requests: requests,
+		errors:   errors,
+		duration: duration,
+	}, nil
+}
+
+// StartRequest starts the span of a request, the span is named after the Client method
+func (i *Instrumentation) StartRequest(ctx context.Context, info redash.RequestInfo) (context.Context, func(int, error)) {
+	name := info.Operation
+	if name == "" {
+		name = info.Method + " " + info.Endpoint
+	}
+
+	attributes := []attribute.KeyValue{
+		OperationKey.String(info.Operation),
+		EndpointKey.String(info.Endpoint),
+		ResourceKey.String(info.Resource),
+		MethodKey.String(info.Method),
+	}
+	spanAttributes := append([]attribute.KeyValue{}, attributes...)
+	if info.ResourceID != "" {
+		spanAttributes = append(spanAttributes, ResourceIDKey.String(info.ResourceID))
+	}
+	if info.Org != "" {
+		spanAttributes = append(spanAttributes, OrgKey.String(info.Org))
+	}
+	if info.Attempt > 1 {
+		spanAttributes = append(spanAttributes, ResendKey.Int(info.Attempt-1))
+	}
+
+	ctx, span := i.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(spanAttributes...))
+	start := time.Now()
+
+	return ctx, func(status int, err error) {
+		failed := err != nil || status >= 400
+		switch {
+		case err != nil:
+			attributes = append(attributes, ErrorTypeKey.String("request"))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		default:
+			attributes = append(attributes, StatusKey.Int(status))
+			span.SetAttributes(StatusKey.Int(status))
+			if failed {
+				attributes = append(attributes, ErrorTypeKey.String(strconv.Itoa(status)))
+				span.SetStatus(codes.Error, "HTTP "+strconv.Itoa(status))
+			}
+		}
+		span.End()
+
+		set := metric.WithAttributes(attributes...)
+		i.requests.Add(ctx, 1, set)
+		i.duration.Record(ctx, time.Since(start).Seconds(), set)
+		if failed {
+			i.errors.Add(ctx, 1, set)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package otelredash
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstrumentation(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/42",
+		httpmock.NewStringResponder(200, `{"id": 42, "name": "Revenue"}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/43",
+		httpmock.NewStringResponder(404, `{"message": "Not found"}`))
+
+	spans := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	instrumentation, err := New(Options{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	assert.Nil(err)
+
+	c, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy", Instrumentation: instrumentation})
+
+	_, err = c.GetQuery(42)
+	assert.Nil(err)
+	_, err = c.GetQuery(43)
+	assert.NotNil(err)
+
+	stubs := spans.GetSpans()
+	assert.Len(stubs, 2)
+	assert.Equal("GetQuery", stubs[0].Name)
+	assert.Contains(stubs[0].Attributes, ResourceKey.String("queries"))
+	assert.Contains(stubs[0].Attributes, ResourceIDKey.String("42"))
+	assert.Contains(stubs[0].Attributes, EndpointKey.String("/api/queries/{id}"))
+	assert.Contains(stubs[0].Attributes, StatusKey.Int(200))
+	assert.Equal(codes.Unset, stubs[0].Status.Code)
+	assert.Equal(codes.Error, stubs[1].Status.Code)
+
+	metrics := metricdata.ResourceMetrics{}
+	assert.Nil(reader.Collect(context.Background(), &metrics))
+	assert.Len(metrics.ScopeMetrics, 1)
+
+	counts := map[string]int64{}
+	for _, m := range metrics.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			for _, point := range data.DataPoints {
+				status, _ := point.Attributes.Value(StatusKey)
+				counts[m.Name+" "+status.Emit()] += point.Value
+			}
+		case metricdata.Histogram[float64]:
+			for _, point := range data.DataPoints {
+				endpoint, _ := point.Attributes.Value(EndpointKey)
+				assert.Equal("/api/queries/{id}", endpoint.AsString())
+				counts[m.Name] += int64(point.Count)
+			}
+		}
+	}
+	assert.Equal(map[string]int64{
+		"redash.client.requests 200":     1,
+		"redash.client.requests 404":     1,
+		"redash.client.errors 404":       1,
+		"redash.client.request.duration": 2,
+	}, counts)
+}
+
+func TestInstrumentationRequestError(t *testing.T) {
+	assert := assert.New(t)
+
+	spans := tracetest.NewInMemoryExporter()
+	instrumentation, _ := New(Options{TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))})
+
+	_, end := instrumentation.StartRequest(context.Background(), redash.RequestInfo{Method: "GET", Endpoint: "/api/session", Resource: "session", Attempt: 2})
+	end(0, context.DeadlineExceeded)
+
+	stubs := spans.GetSpans()
+	assert.Len(stubs, 1)
+	assert.Equal("GET /api/session", stubs[0].Name)
+	assert.Contains(stubs[0].Attributes, attribute.Int("http.request.resend_count", 1))
+	assert.Equal(codes.Error, stubs[0].Status.Code)
+	assert.Len(stubs[0].Events, 1)
+}
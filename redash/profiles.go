@@ -0,0 +1,177 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the configuration file, a set of named profiles
+type ConfigFile struct {
+	DefaultProfile string             `yaml:"default_profile"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// Profile configures a Redash instance. APIKeyCommand is run by the shell when
+// APIKey is empty, its output is the key, so keys can come from a password manager.
+// The command runs again when a request is rejected with a 401, see CommandCredentials.
+type Profile struct {
+	URL           string        `yaml:"url"`
+	Org           string        `yaml:"org"`
+	APIKey        string        `yaml:"api_key"`
+	APIKeyCommand string        `yaml:"api_key_command"`
+	StrictMode    bool          `yaml:"strict_mode"`
+	Timeout       time.Duration `yaml:"timeout"`
+}
+
+// DefaultConfigPath returns $REDASH_CONFIG, or ~/.redash/config.yaml when it is not set
+func DefaultConfigPath() string {
+	if path := os.Getenv("REDASH_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".redash", "config.yaml")
+}
+
+// LoadConfigFile reads a configuration file
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := ConfigFile{}
+	if err := yaml.Unmarshal(body, &file); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return &file, nil
+}
+
+// Config returns the *Config of a profile, the default profile when name is empty
+func (f *ConfigFile) Config(name string) (*Config, error) {
+	if name == "" {
+		name = f.DefaultProfile
+	}
+	if name == "" {
+		return &Config{}, nil
+	}
+
+	profile, ok := f.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q is not defined", name)
+	}
+
+	config := &Config{
+		RedashURI:  profile.URL,
+		OrgSlug:    profile.Org,
+		APIKey:     profile.APIKey,
+		StrictMode: profile.StrictMode,
+		Timeout:    profile.Timeout,
+	}
+
+	if config.APIKey == "" && profile.APIKeyCommand != "" {
+		credentials := NewCommandCredentials(profile.APIKeyCommand)
+		if _, err := credentials.APIKey(); err != nil {
+			return nil, fmt.Errorf("api_key_command of profile %q: %s", name, err)
+		}
+		config.Credentials = credentials
+	}
+
+	return config, nil
+}
+
+func runKeyCommand(command string) (string, error) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// LoadConfig returns the *Config of a profile with environment overrides applied.
+// The file defaults to DefaultConfigPath() and may be missing unless it was named, the profile
+// defaults to $REDASH_PROFILE and then to the default profile of the file.
+// REDASH_URL, REDASH_ORG, REDASH_API_KEY, REDASH_STRICT_MODE and REDASH_TIMEOUT override the profile.
+func LoadConfig(path, profile string) (*Config, error) {
+	explicit := path != "" || os.Getenv("REDASH_CONFIG") != ""
+	if path == "" {
+		path = DefaultConfigPath()
+	}
+
+	file, err := LoadConfigFile(path)
+	if os.IsNotExist(err) && !explicit {
+		file, err = &ConfigFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == "" {
+		profile = os.Getenv("REDASH_PROFILE")
+	}
+	config, err := file.Config(profile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	if value := os.Getenv("REDASH_URL"); value != "" {
+		config.RedashURI = value
+	}
+	if value := os.Getenv("REDASH_ORG"); value != "" {
+		config.OrgSlug = value
+	}
+	if value := os.Getenv("REDASH_API_KEY"); value != "" {
+		config.APIKey = value
+		config.Credentials = nil
+	}
+	if value := os.Getenv("REDASH_STRICT_MODE"); value != "" {
+		config.StrictMode, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("REDASH_STRICT_MODE: %s", err)
+		}
+	}
+	if value := os.Getenv("REDASH_TIMEOUT"); value != "" {
+		config.Timeout, err = time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("REDASH_TIMEOUT: %s", err)
+		}
+	}
+
+	return config, nil
+}
+
+// NewClientFromProfile returns a *Client for a profile of the default configuration file,
+// see LoadConfig
+func NewClientFromProfile(name string) (*Client, error) {
+	config, err := LoadConfig("", name)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(config)
+}
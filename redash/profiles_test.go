@@ -0,0 +1,118 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const configFile = `
+default_profile: staging
+profiles:
+  staging:
+    url: https://staging.acme/
+    api_key: StAgInGkEy
+    timeout: 30s
+  prod:
+    url: https://prod.acme/
+    org: acme-eu
+    api_key_command: echo PrOdKeY
+    strict_mode: true
+  broken:
+    url: https://broken.acme/
+    api_key_command: echo oops >&2; exit 3
+`
+
+func writeConfigFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(configFile), 0644); err != nil {
+		panic(err.Error())
+	}
+
+	for _, name := range []string{"REDASH_CONFIG", "REDASH_PROFILE", "REDASH_URL", "REDASH_ORG", "REDASH_API_KEY", "REDASH_STRICT_MODE", "REDASH_TIMEOUT"} {
+		t.Setenv(name, "")
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	assert := assert.New(t)
+	path := writeConfigFile(t)
+
+	config, err := LoadConfig(path, "")
+	assert.Nil(err)
+	assert.Equal(&Config{RedashURI: "https://staging.acme/", APIKey: "StAgInGkEy", Timeout: 30 * time.Second}, config)
+
+	config, err = LoadConfig(path, "prod")
+	assert.Nil(err)
+	assert.Equal("https://prod.acme/", config.RedashURI)
+	assert.Equal("acme-eu", config.OrgSlug)
+	assert.True(config.StrictMode)
+	assert.Equal("", config.APIKey)
+	key, err := config.Credentials.APIKey()
+	assert.Nil(err)
+	assert.Equal("PrOdKeY", key)
+
+	_, err = LoadConfig(path, "broken")
+	assert.EqualError(err, path+`: api_key_command of profile "broken": exit status 3: oops`)
+
+	_, err = LoadConfig(path, "qa")
+	assert.EqualError(err, path+`: profile "qa" is not defined`)
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"), "")
+	assert.NotNil(err)
+}
+
+func TestLoadConfigEnvironment(t *testing.T) {
+	assert := assert.New(t)
+	path := writeConfigFile(t)
+
+	t.Setenv("REDASH_CONFIG", path)
+	t.Setenv("REDASH_PROFILE", "prod")
+	t.Setenv("REDASH_ORG", "acme-us")
+	t.Setenv("REDASH_API_KEY", "EnVkEy")
+	t.Setenv("REDASH_TIMEOUT", "1m")
+	t.Setenv("REDASH_STRICT_MODE", "false")
+
+	config, err := LoadConfig("", "")
+	assert.Nil(err)
+	assert.Equal(&Config{RedashURI: "https://prod.acme/", OrgSlug: "acme-us", APIKey: "EnVkEy", Timeout: time.Minute}, config)
+
+	c, err := NewClientFromProfile("staging")
+	assert.Nil(err)
+	assert.Equal("https://staging.acme/", c.Config.RedashURI)
+	assert.Equal("EnVkEy", c.Config.APIKey)
+
+	// Without a configuration file the environment is enough
+	t.Setenv("REDASH_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+	_, err = NewClientFromProfile("")
+	assert.NotNil(err)
+
+	t.Setenv("REDASH_CONFIG", "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("REDASH_PROFILE", "")
+	t.Setenv("REDASH_URL", "https://env.acme/")
+	c, err = NewClientFromProfile("")
+	assert.Nil(err)
+	assert.Equal("https://env.acme/", c.Config.RedashURI)
+
+	t.Setenv("REDASH_TIMEOUT", "soon")
+	_, err = LoadConfig("", "")
+	assert.EqualError(err, `REDASH_TIMEOUT: time: invalid duration "soon"`)
+}
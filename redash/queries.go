@@ -150,7 +150,10 @@ type QueryOptionsParameter struct {
 	Name  string `json:"name"`
 	Title string `json:"title"`
 
+	// ParentQueryId is the ID of the query the parameter belongs to, QueryID the
+	// query a query-based dropdown reads its values from
 	ParentQueryId int `json:"parentQueryId"`
+	QueryID       int `json:"queryId,omitempty"`
 
 	Locals []interface{} `json:"locals"`
 
@@ -229,6 +232,28 @@ func (c *Client) GetQueries() (*QueryList, error) {
 	return queries, nil
 }
 
+// ListQueries returns a paginated list of Redash queries
+func (c *Client) ListQueries(page, pageSize int) (*QueryList, error) {
+	path := "/api/queries"
+
+	queryParams := url.Values{}
+	queryParams.Add("page", strconv.Itoa(page))
+	queryParams.Add("page_size", strconv.Itoa(pageSize))
+	response, err := c.get(path, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	queries := new(QueryList)
+	err = json.NewDecoder(response.Body).Decode(queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return queries, nil
+}
+
 // GetQuery returns a specific Redash query by its ID
 func (c *Client) GetQuery(id int) (*Query, error) {
 	path := "/api/queries/" + strconv.Itoa(id)
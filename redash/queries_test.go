@@ -32,6 +32,24 @@ func TestGetQueries(t *testing.T) {
 	assert.Equal(3, len(queries.Results))
 }
 
+func TestListQueries(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries?page=2&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 101, "page": 2, "page_size": 100, "results": [{"id": 101, "name": "Last"}]}`))
+
+	queries, err := c.ListQueries(2, 100)
+	assert.Nil(err)
+
+	assert.Equal(101, queries.Count)
+	assert.Equal(1, len(queries.Results))
+	assert.Equal("Last", queries.Results[0].Name)
+}
+
 func TestGetQuery(t *testing.T) {
 	assert := assert.New(t)
 	httpmock.Activate()
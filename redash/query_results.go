@@ -0,0 +1,168 @@
+package redash
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Column types reported by Redash in query results
+const (
+	ColumnTypeInteger  = "integer"
+	ColumnTypeFloat    = "float"
+	ColumnTypeBoolean  = "boolean"
+	ColumnTypeString   = "string"
+	ColumnTypeDatetime = "datetime"
+	ColumnTypeDate     = "date"
+)
+
+// QueryResult object structure from Redash's /api/query_results/<ID> endpoint
+type QueryResult struct {
+	// Base Data
+	ID           int    `json:"id"`
+	QueryHash    string `json:"query_hash"`
+	Query        string `json:"query"`
+	DataSourceID int    `json:"data_source_id"`
+
+	// Data
+	Data QueryResultData `json:"data"`
+
+	// Metadata
+	Runtime     float64   `json:"runtime"`
+	RetrievedAt time.Time `json:"retrieved_at"`
+}
+
+type QueryResultData struct {
+	Columns []QueryResultColumn      `json:"columns"`
+	Rows    []map[string]interface{} `json:"rows"`
+}
+
+type QueryResultColumn struct {
+	Name         string `json:"name"`
+	FriendlyName string `json:"friendly_name"`
+	Type         string `json:"type"`
+}
+
+type queryResultResponse struct {
+	QueryResult QueryResult `json:"query_result"`
+}
+
+// QueryExecutePayload structure for executing a query
+type QueryExecutePayload struct {
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	MaxAge     int                    `json:"max_age"`
+}
+
+// QueryExecution is the outcome of executing a query, either a cached result or a job to wait for
+type QueryExecution struct {
+	Job         *Job         `json:"job"`
+	QueryResult *QueryResult `json:"query_result"`
+}
+
+// queryPollInterval is how often RunQuery checks on the execution job
+var queryPollInterval = time.Second
+
+// GetQueryResult returns a specific Redash query result by its ID
+func (c *Client) GetQueryResult(id int) (*QueryResult, error) {
+	path := "/api/query_results/" + strconv.Itoa(id)
+
+	queryParams := url.Values{}
+	response, err := c.get(path, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	result := new(queryResultResponse)
+	err = json.NewDecoder(response.Body).Decode(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.QueryResult, nil
+}
+
+// GetQueryResults returns the latest cached result of a Redash query without executing it.
+// Unlike GetLatestQueryResult it works with the query's own API key, see Client.ForQuery.
+func (c *Client) GetQueryResults(queryId int) (*QueryResult, error) {
+	path := "/api/queries/" + strconv.Itoa(queryId) + "/results.json"
+
+	queryParams := url.Values{}
+	response, err := c.get(path, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	result := new(queryResultResponse)
+	err = json.NewDecoder(response.Body).Decode(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.QueryResult, nil
+}
+
+// GetLatestQueryResult returns the most recent result of a Redash query
+func (c *Client) GetLatestQueryResult(queryId int) (*QueryResult, error) {
+	query, err := c.GetQuery(queryId)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.LatestQueryDataID == 0 {
+		return nil, fmt.Errorf("query %d has no results yet", queryId)
+	}
+
+	return c.GetQueryResult(query.LatestQueryDataID)
+}
+
+// ExecuteQuery starts executing a query. Redash answers with a result when one younger
+// than MaxAge seconds is cached, otherwise with the job running the query.
+func (c *Client) ExecuteQuery(queryId int, executePayload *QueryExecutePayload) (*QueryExecution, error) {
+	path := "/api/queries/" + strconv.Itoa(queryId) + "/results"
+
+	payload, err := json.Marshal(executePayload)
+	if err != nil {
+		return nil, err
+	}
+
+	queryParams := url.Values{}
+	response, err := c.post(path, string(payload), queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	execution := new(QueryExecution)
+	err = json.NewDecoder(response.Body).Decode(execution)
+	if err != nil {
+		return nil, err
+	}
+
+	return execution, nil
+}
+
+// RunQuery executes a query without using cached results and waits for its result
+func (c *Client) RunQuery(queryId int, parameters map[string]interface{}, timeout time.Duration) (*QueryResult, error) {
+	execution, err := c.ExecuteQuery(queryId, &QueryExecutePayload{Parameters: parameters})
+	if err != nil {
+		return nil, err
+	}
+
+	if execution.QueryResult != nil {
+		return execution.QueryResult, nil
+	}
+	if execution.Job == nil {
+		return nil, fmt.Errorf("query %d returned neither a result nor a job", queryId)
+	}
+
+	job, err := c.WaitForJob(execution.Job.ID, queryPollInterval, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetQueryResult(job.QueryResultID)
+}
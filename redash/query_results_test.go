@@ -0,0 +1,116 @@
+package redash
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetQueryResult(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	body, err := ioutil.ReadFile("testdata/get-query-result.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/query_results/3919563",
+		httpmock.NewStringResponder(200, string(body)))
+
+	result, err := c.GetQueryResult(3919563)
+	assert.Nil(err)
+
+	assert.Equal(3919563, result.ID)
+	assert.Equal(2, result.DataSourceID)
+	assert.Equal(4, len(result.Data.Columns))
+	assert.Equal("revenue", result.Data.Columns[1].Name)
+	assert.Equal(ColumnTypeFloat, result.Data.Columns[1].Type)
+	assert.Equal(2, len(result.Data.Rows))
+	assert.Equal("NZ", result.Data.Rows[0]["country"])
+}
+
+func TestGetLatestQueryResult(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	query, err := ioutil.ReadFile("testdata/get-query.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/1",
+		httpmock.NewStringResponder(200, string(query)))
+
+	body, err := ioutil.ReadFile("testdata/get-query-result.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/query_results/3919563",
+		httpmock.NewStringResponder(200, string(body)))
+
+	result, err := c.GetLatestQueryResult(1)
+	assert.Nil(err)
+	assert.Equal(3919563, result.ID)
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/2",
+		httpmock.NewStringResponder(200, `{"id": 2, "latest_query_data_id": null}`))
+
+	_, err = c.GetLatestQueryResult(2)
+	assert.NotNil(err)
+}
+
+func TestExecuteQuery(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/1/results",
+		httpmock.NewStringResponder(200, `{"job": {"id": "f2c3", "status": 1}}`))
+
+	execution, err := c.ExecuteQuery(1, &QueryExecutePayload{Parameters: map[string]interface{}{"country": "NZ"}, MaxAge: 60})
+	assert.Nil(err)
+	assert.Nil(execution.QueryResult)
+	assert.Equal("f2c3", execution.Job.ID)
+	assert.Equal(JobStatusPending, execution.Job.Status)
+}
+
+func TestRunQuery(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	queryPollInterval = time.Millisecond
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/1/results",
+		httpmock.NewStringResponder(200, `{"job": {"id": "f2c3", "status": 1}}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/f2c3",
+		httpmock.NewStringResponder(200, `{"job": {"id": "f2c3", "status": 3, "query_result_id": 3919563}}`))
+
+	body, err := ioutil.ReadFile("testdata/get-query-result.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/query_results/3919563",
+		httpmock.NewStringResponder(200, string(body)))
+
+	result, err := c.RunQuery(1, nil, time.Minute)
+	assert.Nil(err)
+	assert.Equal(3919563, result.ID)
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/f2c3",
+		httpmock.NewStringResponder(200, `{"job": {"id": "f2c3", "status": 4, "error": "relation does not exist"}}`))
+
+	_, err = c.RunQuery(1, nil, time.Minute)
+	assert.EqualError(err, "job f2c3 failed: relation does not exist")
+}
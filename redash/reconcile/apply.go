@@ -0,0 +1,228 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package reconcile
+
+import (
+	"fmt"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// Apply runs the actions of a plan in order and records every created or deleted object
+// in the state. It stops at the first failure, the state then reflects what was applied
+// and should be saved either way so the next plan picks up from there.
+func (r *Reconciler) Apply(plan *Plan) error {
+	for i := range plan.Actions {
+		action := &plan.Actions[i]
+		if !action.planned {
+			return fmt.Errorf("%s: not computed by Plan, a decoded plan cannot be applied", action)
+		}
+
+		var err error
+		switch action.Kind {
+		case KindDashboard:
+			err = r.applyDashboard(action)
+		case KindQuery:
+			err = r.applyQuery(action)
+		case KindVisualization:
+			err = r.applyVisualization(action)
+		case KindWidget:
+			err = r.applyWidget(action)
+		default:
+			err = fmt.Errorf("unknown kind")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s", action, err)
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the ID of an object created earlier in the plan or in a previous run
+func (r *Reconciler) resolve(address string) (int, error) {
+	resource, ok := r.state.Resources[address]
+	if !ok {
+		return 0, fmt.Errorf("%s is not deployed", address)
+	}
+	return resource.ID, nil
+}
+
+func (r *Reconciler) applyDashboard(action *Action) error {
+	if action.Op == OpDelete {
+		if err := r.client.ArchiveDashboard(action.slug); err != nil {
+			return err
+		}
+		delete(r.state.Resources, action.Address)
+		return nil
+	}
+
+	spec := action.dashboard
+	id, slug := action.ID, action.slug
+	if action.Op == OpCreate {
+		created, err := r.client.CreateDashboard(&redash.DashboardCreatePayload{Name: spec.Name})
+		if err != nil {
+			return err
+		}
+		id, slug = created.ID, created.Slug
+		r.state.Resources[action.Address] = Resource{ID: id, Slug: slug}
+	}
+
+	updated, err := r.client.UpdateDashboard(id, &redash.DashboardUpdatePayload{
+		Name:                    spec.Name,
+		Slug:                    slug,
+		IsFavorite:              action.isFavorite,
+		IsDraft:                 spec.IsDraft,
+		DashboardFiltersEnabled: spec.DashboardFiltersEnabled,
+		Tags:                    r.ownerTagged(spec.Tags),
+	})
+	if err != nil {
+		return err
+	}
+	r.state.Resources[action.Address] = Resource{ID: updated.ID, Slug: updated.Slug}
+
+	return nil
+}
+
+func (r *Reconciler) applyQuery(action *Action) error {
+	if action.Op == OpDelete {
+		if err := r.client.ArchiveQuery(action.ID); err != nil {
+			return err
+		}
+		delete(r.state.Resources, action.Address)
+		return nil
+	}
+
+	spec := action.query
+	if action.Op == OpCreate {
+		created, err := r.client.CreateQuery(&redash.QueryCreatePayload{
+			Name:         spec.Name,
+			Description:  spec.Description,
+			DataSourceID: action.dataSourceID,
+			Query:        spec.Query,
+			Options:      spec.Options,
+			Tags:         r.ownerTagged(spec.Tags),
+			Schedule:     spec.Schedule,
+		})
+		if err != nil {
+			return err
+		}
+		r.state.Resources[action.Address] = Resource{ID: created.ID}
+		return nil
+	}
+
+	_, err := r.client.UpdateQuery(action.ID, &redash.QueryUpdatePayload{
+		Name:         spec.Name,
+		Description:  spec.Description,
+		DataSourceID: action.dataSourceID,
+		Query:        spec.Query,
+		Options:      spec.Options,
+		IsDraft:      action.isDraft,
+		Version:      action.version,
+		Tags:         r.ownerTagged(spec.Tags),
+		Schedule:     spec.Schedule,
+	})
+	return err
+}
+
+func (r *Reconciler) applyVisualization(action *Action) error {
+	if action.Op == OpDelete {
+		if err := r.client.DeleteVisualization(action.ID); err != nil {
+			return err
+		}
+		delete(r.state.Resources, action.Address)
+		return nil
+	}
+
+	spec := action.visualization
+	if action.Op == OpCreate {
+		queryID, err := r.resolve(action.parent)
+		if err != nil {
+			return err
+		}
+		created, err := r.client.CreateVisualization(&redash.VisualizationCreatePayload{
+			Name:        spec.Name,
+			Description: spec.Description,
+			Type:        spec.Type,
+			Options:     spec.Options,
+			QueryId:     queryID,
+		})
+		if err != nil {
+			return err
+		}
+		r.state.Resources[action.Address] = Resource{ID: created.ID}
+		return nil
+	}
+
+	_, err := r.client.UpdateVisualization(action.ID, &redash.VisualizationUpdatePayload{
+		Name:        spec.Name,
+		Description: spec.Description,
+		Type:        spec.Type,
+		Options:     spec.Options,
+	})
+	return err
+}
+
+func (r *Reconciler) applyWidget(action *Action) error {
+	if action.Op == OpDelete {
+		if err := r.client.DeleteWidget(action.ID); err != nil {
+			return err
+		}
+		delete(r.state.Resources, action.Address)
+		return nil
+	}
+
+	spec := action.widget
+	options := redash.WidgetOptions{
+		IsHidden:          spec.IsHidden,
+		ParameterMappings: spec.ParameterMappings,
+		Position:          spec.Position,
+	}
+
+	var visualizationID *int
+	if action.target != "" {
+		id, err := r.resolve(action.target)
+		if err != nil {
+			return err
+		}
+		visualizationID = &id
+	}
+
+	if action.Op == OpCreate {
+		dashboardID, err := r.resolve(action.parent)
+		if err != nil {
+			return err
+		}
+		created, err := r.client.CreateWidget(&redash.WidgetCreatePayload{
+			DashboardID:     dashboardID,
+			Text:            spec.Text,
+			Width:           1,
+			VisualizationID: visualizationID,
+			Options:         options,
+		})
+		if err != nil {
+			return err
+		}
+		r.state.Resources[action.Address] = Resource{ID: created.ID}
+		return nil
+	}
+
+	_, err := r.client.UpdateWidget(action.ID, &redash.WidgetUpdatePayload{
+		Text:            spec.Text,
+		Width:           1,
+		VisualizationID: visualizationID,
+		Options:         options,
+	})
+	return err
+}
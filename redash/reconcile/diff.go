@@ -0,0 +1,117 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FieldDiff is a changed field, nested fields are separated by dots
+type FieldDiff struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.Field, render(d.Old), render(d.New))
+}
+
+func render(value interface{}) string {
+	if isEmpty(value) {
+		return "(none)"
+	}
+	body, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(body)
+}
+
+// normalize converts a value to its JSON representation made of maps, slices and scalars
+func normalize(value interface{}) interface{} {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(body, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// isEmpty treats zero values the same as absent ones, so defaults never show up as changes
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// diff compares two views of an object, descending into objects so that option
+// changes are reported field by field. Arrays are compared as a whole.
+func diff(old, new interface{}) []FieldDiff {
+	return diffNormalized("", normalize(old), normalize(new))
+}
+
+func diffNormalized(path string, old, new interface{}) []FieldDiff {
+	if isEmpty(old) && isEmpty(new) {
+		return nil
+	}
+
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if (oldIsMap || old == nil) && (newIsMap || new == nil) {
+		keys := []string{}
+		for key := range oldMap {
+			keys = append(keys, key)
+		}
+		for key := range newMap {
+			if _, ok := oldMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		diffs := []FieldDiff{}
+		for _, key := range keys {
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			diffs = append(diffs, diffNormalized(field, oldMap[key], newMap[key])...)
+		}
+		return diffs
+	}
+
+	if reflect.DeepEqual(old, new) {
+		return nil
+	}
+	return []FieldDiff{{Field: path, Old: old, New: new}}
+}
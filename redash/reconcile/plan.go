@@ -0,0 +1,510 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Package reconcile converges Redash towards dashboards declared as dashcode documents.
+// Plan compares the documents with what is deployed and lists the field level changes,
+// Apply carries them out in dependency order. Managed objects are tracked in a State,
+// queries and dashboards are also tagged with the owner tag so they stand out in Redash
+// and are never changed once someone takes them over by removing the tag.
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/AlmirKadric/redash-client-go/redash/dashcode"
+)
+
+// DefaultOwnerTag is the tag added to managed queries and dashboards
+const DefaultOwnerTag = "managed-by:redash-client-go"
+
+// Kinds of managed objects, in the order they are created
+const (
+	KindDashboard     = "dashboard"
+	KindQuery         = "query"
+	KindVisualization = "visualization"
+	KindWidget        = "widget"
+)
+
+var kindOrder = []string{KindDashboard, KindQuery, KindVisualization, KindWidget}
+
+// Operations of an Action
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Options configures a Reconciler
+type Options struct {
+	// OwnerTag replaces DefaultOwnerTag
+	OwnerTag string
+}
+
+// Action is a single change of a plan. Actions serialize for review, but carry the desired
+// specs in unexported fields, so only an action returned by Plan can be applied.
+type Action struct {
+	Op      string      `json:"op"`
+	Kind    string      `json:"kind"`
+	Address string      `json:"address"`
+	ID      int         `json:"id,omitempty"`
+	Diffs   []FieldDiff `json:"diffs,omitempty"`
+
+	// Desired specs and the addresses they depend on
+	dashboard     *dashcode.DashboardSpec
+	query         *dashcode.QuerySpec
+	visualization *dashcode.VisualizationSpec
+	widget        *dashcode.WidgetSpec
+	parent        string
+	target        string
+	dataSourceID  int
+
+	// Current values sent back unchanged on update
+	slug       string
+	version    int
+	isDraft    bool
+	isFavorite bool
+
+	// planned is set by Plan, a decoded action has lost its specs
+	planned bool
+}
+
+func (a *Action) String() string {
+	symbol := map[string]string{OpCreate: "+", OpUpdate: "~", OpDelete: "-"}[a.Op]
+	if a.ID == 0 {
+		return fmt.Sprintf("%s %s", symbol, a.Address)
+	}
+	return fmt.Sprintf("%s %s (%d)", symbol, a.Address, a.ID)
+}
+
+// Plan lists the actions needed to converge, in the order Apply runs them.
+// A plan is applied in the process that computed it, a decoded plan can only be reviewed.
+type Plan struct {
+	Actions []Action `json:"actions"`
+}
+
+// Empty returns true when everything is up to date
+func (p *Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// Count returns the number of actions with the given operation
+func (p *Plan) Count(op string) int {
+	count := 0
+	for _, action := range p.Actions {
+		if action.Op == op {
+			count++
+		}
+	}
+	return count
+}
+
+// String renders the plan for review, one action per line followed by its field diffs
+func (p *Plan) String() string {
+	builder := strings.Builder{}
+	for i := range p.Actions {
+		action := &p.Actions[i]
+		builder.WriteString(action.String() + "\n")
+		for _, diff := range action.Diffs {
+			builder.WriteString("    " + diff.String() + "\n")
+		}
+	}
+	builder.WriteString(fmt.Sprintf("Plan: %d to create, %d to update, %d to delete\n",
+		p.Count(OpCreate), p.Count(OpUpdate), p.Count(OpDelete)))
+	return builder.String()
+}
+
+// Reconciler plans and applies changes for a set of documents
+type Reconciler struct {
+	client  *redash.Client
+	state   *State
+	options Options
+}
+
+// NewReconciler returns a *Reconciler recording managed objects in state
+func NewReconciler(client *redash.Client, state *State, options Options) *Reconciler {
+	if options.OwnerTag == "" {
+		options.OwnerTag = DefaultOwnerTag
+	}
+	return &Reconciler{client: client, state: state, options: options}
+}
+
+// State returns the state, updated by Apply
+func (r *Reconciler) State() *State {
+	return r.state
+}
+
+// Address helpers, the dashboard slug scopes everything declared in its document
+func dashboardAddress(slug string) string {
+	return KindDashboard + "." + slug
+}
+
+func queryAddress(slug, query string) string {
+	return KindQuery + "." + slug + "." + query
+}
+
+func visualizationAddress(slug, query, visualization string) string {
+	return KindVisualization + "." + slug + "." + query + "." + visualization
+}
+
+func widgetAddress(slug, name string, index int) string {
+	return fmt.Sprintf("%s.%s.%s[%d]", KindWidget, slug, name, index)
+}
+
+func kindOf(address string) string {
+	return strings.SplitN(address, ".", 2)[0]
+}
+
+// planner holds what is needed while a plan is computed
+type planner struct {
+	*Reconciler
+	dataSourceIDs   map[string]int
+	dataSourceNames map[int]string
+	desired         map[string]bool
+	actions         map[string][]Action
+}
+
+// Plan compares the documents with the deployed objects. Every document must have a
+// slug, which scopes the addresses of its objects. Managed objects that are no longer
+// declared are deleted.
+func (r *Reconciler) Plan(documents ...*dashcode.Document) (*Plan, error) {
+	slugs := map[string]bool{}
+	for _, document := range documents {
+		if err := document.Validate(); err != nil {
+			return nil, err
+		}
+		slug := document.Dashboard.Slug
+		if slug == "" || slugs[slug] {
+			return nil, fmt.Errorf("dashboard %q has an empty or duplicate slug", document.Dashboard.Name)
+		}
+		slugs[slug] = true
+	}
+
+	dataSources, err := r.client.GetDataSources()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &planner{
+		Reconciler:      r,
+		dataSourceIDs:   map[string]int{},
+		dataSourceNames: map[int]string{},
+		desired:         map[string]bool{},
+		actions:         map[string][]Action{},
+	}
+	for _, dataSource := range *dataSources {
+		p.dataSourceIDs[dataSource.Name] = dataSource.ID
+		p.dataSourceNames[dataSource.ID] = dataSource.Name
+	}
+
+	for _, document := range documents {
+		if err := p.planDashboard(&document.Dashboard); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &Plan{}
+	for _, kind := range kindOrder {
+		plan.Actions = append(plan.Actions, p.actions[kind]...)
+	}
+
+	deletes, err := p.planDeletes()
+	if err != nil {
+		return nil, err
+	}
+	plan.Actions = append(plan.Actions, deletes...)
+
+	return plan, nil
+}
+
+func (p *planner) add(action Action, current, desired interface{}) {
+	p.desired[action.Address] = true
+	action.planned = true
+	action.Diffs = diff(current, desired)
+	if action.Op == OpUpdate && len(action.Diffs) == 0 {
+		return
+	}
+	p.actions[action.Kind] = append(p.actions[action.Kind], action)
+}
+
+func (p *planner) owned(address string, id int, tags []string) error {
+	for _, tag := range tags {
+		if tag == p.options.OwnerTag {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s (%d) is not tagged %q anymore, remove it from the state to release it", address, id, p.options.OwnerTag)
+}
+
+func (r *Reconciler) ownerTagged(tags []string) []string {
+	result := []string{r.options.OwnerTag}
+	for _, tag := range tags {
+		if tag != r.options.OwnerTag {
+			result = append(result, tag)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func sortedTags(tags []string) []string {
+	result := append([]string{}, tags...)
+	sort.Strings(result)
+	return result
+}
+
+func (p *planner) planDashboard(spec *dashcode.DashboardSpec) error {
+	address := dashboardAddress(spec.Slug)
+	action := Action{Op: OpCreate, Kind: KindDashboard, Address: address, dashboard: spec}
+	desired := map[string]interface{}{
+		"name":                      spec.Name,
+		"tags":                      p.ownerTagged(spec.Tags),
+		"is_draft":                  spec.IsDraft,
+		"dashboard_filters_enabled": spec.DashboardFiltersEnabled,
+	}
+
+	var current map[string]interface{}
+	var dashboard *redash.Dashboard
+	if resource, ok := p.state.Resources[address]; ok {
+		var err error
+		dashboard, err = p.client.GetDashboard(resource.Slug)
+		if err != nil {
+			return fmt.Errorf("%s: %s", address, err)
+		}
+		if err := p.owned(address, dashboard.ID, dashboard.Tags); err != nil {
+			return err
+		}
+
+		action.Op, action.ID = OpUpdate, dashboard.ID
+		action.slug, action.isFavorite = dashboard.Slug, dashboard.IsFavorite
+		current = map[string]interface{}{
+			"name":                      dashboard.Name,
+			"tags":                      sortedTags(dashboard.Tags),
+			"is_draft":                  dashboard.IsDraft,
+			"dashboard_filters_enabled": dashboard.DashboardFiltersEnabled,
+		}
+	}
+	p.add(action, current, desired)
+
+	for i := range spec.Queries {
+		if err := p.planQuery(spec.Slug, &spec.Queries[i]); err != nil {
+			return err
+		}
+	}
+
+	p.planWidgets(spec, dashboard)
+	return nil
+}
+
+func (p *planner) planQuery(slug string, spec *dashcode.QuerySpec) error {
+	address := queryAddress(slug, spec.Key)
+	// Dropdowns need the ID of the query they read from, which only dashcode.Import resolves
+	if len(spec.Dropdowns) > 0 {
+		return fmt.Errorf("%s has query-based dropdowns, which are not supported", address)
+	}
+	dataSourceID, ok := p.dataSourceIDs[spec.DataSource]
+	if !ok {
+		return fmt.Errorf("%s uses unknown data source %q", address, spec.DataSource)
+	}
+
+	action := Action{Op: OpCreate, Kind: KindQuery, Address: address, query: spec, dataSourceID: dataSourceID}
+	desired := map[string]interface{}{
+		"name":        spec.Name,
+		"description": spec.Description,
+		"data_source": spec.DataSource,
+		"query":       spec.Query,
+		"options":     spec.Options,
+		"schedule":    spec.Schedule,
+		"tags":        p.ownerTagged(spec.Tags),
+	}
+
+	var current map[string]interface{}
+	var query *redash.Query
+	if resource, ok := p.state.Resources[address]; ok {
+		var err error
+		query, err = p.client.GetQuery(resource.ID)
+		if err != nil {
+			return fmt.Errorf("%s: %s", address, err)
+		}
+		if err := p.owned(address, query.ID, query.Tags); err != nil {
+			return err
+		}
+
+		dataSource, ok := p.dataSourceNames[query.DataSourceID]
+		if !ok {
+			dataSource = fmt.Sprintf("#%d", query.DataSourceID)
+		}
+
+		// Parameter references to the query itself are not part of the document
+		options := redash.QueryOptions{}
+		for _, parameter := range query.Options.Parameters {
+			parameter.ParentQueryId = 0
+			options.Parameters = append(options.Parameters, parameter)
+		}
+
+		var schedule *redash.QuerySchedule
+		if query.Schedule.Interval != 0 || query.Schedule.Time != "" || query.Schedule.DayOfWeek != "" || query.Schedule.Until != nil {
+			schedule = &query.Schedule
+		}
+
+		action.Op, action.ID = OpUpdate, query.ID
+		action.version, action.isDraft = query.Version, query.IsDraft
+		current = map[string]interface{}{
+			"name":        query.Name,
+			"description": query.Description,
+			"data_source": dataSource,
+			"query":       query.Query,
+			"options":     options,
+			"schedule":    schedule,
+			"tags":        sortedTags(query.Tags),
+		}
+	}
+	p.add(action, current, desired)
+
+	for i := range spec.Visualizations {
+		p.planVisualization(slug, spec, &spec.Visualizations[i], query)
+	}
+
+	return nil
+}
+
+func (p *planner) planVisualization(slug string, query *dashcode.QuerySpec, spec *dashcode.VisualizationSpec, deployed *redash.Query) {
+	address := visualizationAddress(slug, query.Key, spec.Key)
+	action := Action{Op: OpCreate, Kind: KindVisualization, Address: address, visualization: spec, parent: queryAddress(slug, query.Key)}
+	desired := map[string]interface{}{
+		"name":        spec.Name,
+		"description": spec.Description,
+		"type":        spec.Type,
+		"options":     spec.Options,
+	}
+
+	// A visualization deleted outside of the reconciler is created again
+	var current map[string]interface{}
+	if resource, ok := p.state.Resources[address]; ok && deployed != nil {
+		for _, visualization := range deployed.Visualizations {
+			if visualization.ID != resource.ID {
+				continue
+			}
+			action.Op, action.ID = OpUpdate, visualization.ID
+			current = map[string]interface{}{
+				"name":        visualization.Name,
+				"description": visualization.Description,
+				"type":        visualization.Type,
+				"options":     visualization.Options,
+			}
+		}
+	}
+	p.add(action, current, desired)
+}
+
+func (p *planner) planWidgets(spec *dashcode.DashboardSpec, dashboard *redash.Dashboard) {
+	visualizations := map[int]string{}
+	for address, resource := range p.state.Resources {
+		if kindOf(address) == KindVisualization {
+			visualizations[resource.ID] = address
+		}
+	}
+
+	deployed := map[int]redash.WidgetDashboard{}
+	if dashboard != nil {
+		for _, widget := range dashboard.Widgets {
+			deployed[widget.ID] = widget
+		}
+	}
+
+	indexes := map[string]int{}
+	for i := range spec.Widgets {
+		widget := &spec.Widgets[i]
+
+		name, target := "text", ""
+		if !widget.IsText() {
+			name = widget.Query + "." + widget.Visualization
+			target = visualizationAddress(spec.Slug, widget.Query, widget.Visualization)
+		}
+		address := widgetAddress(spec.Slug, name, indexes[name])
+		indexes[name]++
+
+		action := Action{Op: OpCreate, Kind: KindWidget, Address: address, widget: widget, parent: dashboardAddress(spec.Slug), target: target}
+		desired := map[string]interface{}{
+			"text":               widget.Text,
+			"visualization":      target,
+			"is_hidden":          widget.IsHidden,
+			"position":           widget.Position,
+			"parameter_mappings": widget.ParameterMappings,
+		}
+
+		var current map[string]interface{}
+		if resource, ok := p.state.Resources[address]; ok {
+			if existing, ok := deployed[resource.ID]; ok {
+				visualization := ""
+				if !existing.IsText() {
+					visualization, ok = visualizations[existing.Visualization.ID]
+					if !ok {
+						visualization = fmt.Sprintf("#%d", existing.Visualization.ID)
+					}
+				}
+
+				action.Op, action.ID = OpUpdate, existing.ID
+				current = map[string]interface{}{
+					"text":               existing.Text,
+					"visualization":      visualization,
+					"is_hidden":          existing.Options.IsHidden,
+					"position":           existing.Options.Position,
+					"parameter_mappings": existing.Options.ParameterMappings,
+				}
+			}
+		}
+		p.add(action, current, desired)
+	}
+}
+
+// planDeletes removes managed objects that are no longer declared, dependents first
+func (p *planner) planDeletes() ([]Action, error) {
+	byKind := map[string][]Action{}
+	for _, address := range p.state.Addresses() {
+		if p.desired[address] {
+			continue
+		}
+		resource := p.state.Resources[address]
+		action := Action{Op: OpDelete, Kind: kindOf(address), Address: address, ID: resource.ID, slug: resource.Slug, planned: true}
+
+		switch action.Kind {
+		case KindDashboard:
+			dashboard, err := p.client.GetDashboard(resource.Slug)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", address, err)
+			}
+			if err := p.owned(address, dashboard.ID, dashboard.Tags); err != nil {
+				return nil, err
+			}
+		case KindQuery:
+			query, err := p.client.GetQuery(resource.ID)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", address, err)
+			}
+			if err := p.owned(address, query.ID, query.Tags); err != nil {
+				return nil, err
+			}
+		}
+
+		byKind[action.Kind] = append(byKind[action.Kind], action)
+	}
+
+	actions := []Action{}
+	for i := len(kindOrder) - 1; i >= 0; i-- {
+		actions = append(actions, byKind[kindOrder[i]]...)
+	}
+	return actions, nil
+}
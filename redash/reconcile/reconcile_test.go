@@ -0,0 +1,309 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package reconcile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/AlmirKadric/redash-client-go/redash/dashcode"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const manifest = `
+version: 1
+dashboard:
+  name: Sales
+  slug: sales
+  tags: [finance]
+  queries:
+    - key: revenue
+      name: Revenue
+      data_source: Warehouse
+      query: select day, amount from revenue
+      options: {}
+      visualizations:
+        - key: chart
+          name: Revenue by day
+          type: CHART
+          options:
+            globalSeriesType: line
+            legend:
+              enabled: true
+  widgets:
+    - text: "# Sales"
+      position: {col: 0, row: 0, sizeX: 6, sizeY: 2}
+    - query: revenue
+      visualization: chart
+      position: {col: 0, row: 2, sizeX: 3, sizeY: 8}
+`
+
+func newClient() *redash.Client {
+	c, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources",
+		httpmock.NewStringResponder(200, `[{"id": 3, "name": "Warehouse"}]`))
+	return c
+}
+
+func decodeManifest() *dashcode.Document {
+	document, err := dashcode.Decode([]byte(manifest))
+	if err != nil {
+		panic(err.Error())
+	}
+	return document
+}
+
+// capture records request bodies and answers with a fixed response
+func capture(bodies *[]map[string]interface{}, response string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		body := map[string]interface{}{}
+		if req.Body != nil {
+			data, _ := ioutil.ReadAll(req.Body)
+			_ = json.Unmarshal(data, &body)
+		}
+		*bodies = append(*bodies, body)
+		return httpmock.NewStringResponse(200, response), nil
+	}
+}
+
+func TestPlanAndApplyCreate(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reconciler := NewReconciler(newClient(), NewState(), Options{})
+
+	plan, err := reconciler.Plan(decodeManifest())
+	assert.Nil(err)
+	assert.Equal(5, plan.Count(OpCreate))
+	assert.Equal(0, plan.Count(OpUpdate)+plan.Count(OpDelete))
+
+	addresses := []string{}
+	for _, action := range plan.Actions {
+		addresses = append(addresses, action.Address)
+	}
+	assert.Equal([]string{
+		"dashboard.sales",
+		"query.sales.revenue",
+		"visualization.sales.revenue.chart",
+		"widget.sales.text[0]",
+		"widget.sales.revenue.chart[0]",
+	}, addresses)
+	assert.Contains(plan.String(), "+ query.sales.revenue\n    data_source: (none) -> \"Warehouse\"\n")
+	assert.Contains(plan.String(), "    options.legend.enabled: (none) -> true\n")
+	assert.Contains(plan.String(), "Plan: 5 to create, 0 to update, 0 to delete\n")
+
+	dashboards, queries, visualizations, widgets := []map[string]interface{}{}, []map[string]interface{}{}, []map[string]interface{}{}, []map[string]interface{}{}
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards",
+		capture(&dashboards, `{"id": 10, "slug": "sales"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards/10",
+		capture(&dashboards, `{"id": 10, "slug": "sales"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries",
+		capture(&queries, `{"id": 20}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/visualizations",
+		capture(&visualizations, `{"id": 30}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/widgets",
+		capture(&widgets, `{"id": 40}`))
+
+	assert.Nil(reconciler.Apply(plan))
+
+	assert.Equal(2, len(dashboards))
+	assert.Equal([]interface{}{"finance", DefaultOwnerTag}, dashboards[1]["tags"])
+	assert.Equal(float64(3), queries[0]["data_source_id"])
+	assert.Equal([]interface{}{DefaultOwnerTag}, queries[0]["tags"])
+	assert.Equal(float64(20), visualizations[0]["query_id"])
+	assert.Equal(2, len(widgets))
+	assert.Equal(nil, widgets[0]["visualization_id"])
+	assert.Equal(float64(10), widgets[1]["dashboard_id"])
+	assert.Equal(float64(30), widgets[1]["visualization_id"])
+
+	state := reconciler.State()
+	assert.Equal(Resource{ID: 10, Slug: "sales"}, state.Resources["dashboard.sales"])
+	assert.Equal(20, state.Resources["query.sales.revenue"].ID)
+	assert.Equal(30, state.Resources["visualization.sales.revenue.chart"].ID)
+	assert.Equal(40, state.Resources["widget.sales.revenue.chart[0]"].ID)
+}
+
+func deployedState() *State {
+	state := NewState()
+	state.Resources["dashboard.sales"] = Resource{ID: 10, Slug: "sales"}
+	state.Resources["query.sales.revenue"] = Resource{ID: 20}
+	state.Resources["visualization.sales.revenue.chart"] = Resource{ID: 30}
+	state.Resources["widget.sales.text[0]"] = Resource{ID: 40}
+	state.Resources["widget.sales.revenue.chart[0]"] = Resource{ID: 41}
+	state.Resources["widget.sales.text[1]"] = Resource{ID: 42}
+	return state
+}
+
+const deployedDashboard = `{
+	"id": 10, "name": "Sales", "slug": "sales", "tags": ["managed-by:redash-client-go", "finance"],
+	"widgets": [
+		{"id": 40, "text": "# Sales", "visualization": {}, "options": {"position": {"col": 0, "row": 0, "sizeX": 6, "sizeY": 2}}},
+		{"id": 41, "text": "", "visualization": {"id": 30}, "options": {"position": {"col": 0, "row": 2, "sizeX": 3, "sizeY": 8}}},
+		{"id": 42, "text": "Old notes", "visualization": {}, "options": {"position": {"col": 0, "row": 10, "sizeX": 6, "sizeY": 2}}}
+	]
+}`
+
+func TestPlanAndApplyUpdate(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reconciler := NewReconciler(newClient(), deployedState(), Options{})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards/sales",
+		httpmock.NewStringResponder(200, deployedDashboard))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/20",
+		httpmock.NewStringResponder(200, `{
+			"id": 20, "name": "Revenue", "data_source_id": 3, "version": 4,
+			"query": "select day, amount from revenue_v1", "options": {"parameters": []},
+			"tags": ["managed-by:redash-client-go"], "schedule": null,
+			"visualizations": [
+				{"id": 1, "name": "Table", "type": "TABLE", "options": {}},
+				{"id": 30, "name": "Revenue by day", "type": "CHART", "options": {"globalSeriesType": "line", "legend": {"enabled": false}}}
+			]
+		}`))
+
+	plan, err := reconciler.Plan(decodeManifest())
+	assert.Nil(err)
+
+	assert.Equal(3, len(plan.Actions))
+	assert.Equal("~ query.sales.revenue (20)", plan.Actions[0].String())
+	assert.Equal([]FieldDiff{{Field: "query", Old: "select day, amount from revenue_v1", New: "select day, amount from revenue"}}, plan.Actions[0].Diffs)
+	assert.Equal("~ visualization.sales.revenue.chart (30)", plan.Actions[1].String())
+	assert.Equal([]FieldDiff{{Field: "options.legend.enabled", Old: false, New: true}}, plan.Actions[1].Diffs)
+	assert.Equal("- widget.sales.text[1] (42)", plan.Actions[2].String())
+
+	queries, visualizations := []map[string]interface{}{}, []map[string]interface{}{}
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/20",
+		capture(&queries, `{"id": 20}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/visualizations/30",
+		capture(&visualizations, `{"id": 30}`))
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/widgets/42",
+		httpmock.NewStringResponder(200, ``))
+
+	assert.Nil(reconciler.Apply(plan))
+	assert.Equal(float64(4), queries[0]["version"])
+	assert.Equal(1, len(visualizations))
+	_, ok := reconciler.State().Resources["widget.sales.text[1]"]
+	assert.False(ok)
+}
+
+func TestPlanOwnership(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reconciler := NewReconciler(newClient(), deployedState(), Options{OwnerTag: "team:finance"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards/sales",
+		httpmock.NewStringResponder(200, deployedDashboard))
+
+	_, err := reconciler.Plan(decodeManifest())
+	assert.EqualError(err, `dashboard.sales (10) is not tagged "team:finance" anymore, remove it from the state to release it`)
+}
+
+func TestApplyStopsAtFailure(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reconciler := NewReconciler(newClient(), NewState(), Options{})
+	plan, err := reconciler.Plan(decodeManifest())
+	assert.Nil(err)
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards",
+		httpmock.NewStringResponder(200, `{"id": 10, "slug": "sales"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards/10",
+		httpmock.NewStringResponder(200, `{"id": 10, "slug": "sales"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries",
+		httpmock.NewStringResponder(500, `{"message": "oops"}`))
+
+	err = reconciler.Apply(plan)
+	assert.NotNil(err)
+	assert.True(strings.HasPrefix(err.Error(), "+ query.sales.revenue: 500 from POST"))
+	assert.Equal([]string{"dashboard.sales"}, reconciler.State().Addresses())
+}
+
+func TestApplyRejectsDecodedPlan(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reconciler := NewReconciler(newClient(), NewState(), Options{})
+	plan, err := reconciler.Plan(decodeManifest())
+	assert.Nil(err)
+
+	data, err := json.Marshal(plan)
+	assert.Nil(err)
+	decoded := &Plan{}
+	assert.Nil(json.Unmarshal(data, decoded))
+
+	httpmock.ZeroCallCounters()
+	assert.EqualError(reconciler.Apply(decoded), "+ dashboard.sales: not computed by Plan, a decoded plan cannot be applied")
+	assert.Equal(0, httpmock.GetTotalCallCount())
+}
+
+func TestPlanRejectsDropdowns(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	document := decodeManifest()
+	document.Dashboard.Queries[0].Options.Parameters = []redash.QueryOptionsParameter{{Name: "region", Type: "query"}}
+	document.Dashboard.Queries[0].Dropdowns = map[string]string{"region": "revenue"}
+
+	_, err := NewReconciler(newClient(), NewState(), Options{}).Plan(document)
+	assert.EqualError(err, "query.sales.revenue has query-based dropdowns, which are not supported")
+}
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	diffs := diff(
+		map[string]interface{}{"name": "a", "tags": []string{}, "options": map[string]interface{}{"x": 1, "y": map[string]interface{}{"z": true}}},
+		map[string]interface{}{"name": "b", "hidden": false, "options": map[string]interface{}{"x": 1, "y": map[string]interface{}{"z": false}}},
+	)
+	assert.Equal([]FieldDiff{
+		{Field: "name", Old: "a", New: "b"},
+		{Field: "options.y.z", Old: true, New: false},
+	}, diffs)
+
+	assert.Equal(0, len(diff(nil, map[string]interface{}{"text": "", "mappings": map[string]string{}})))
+	assert.Equal("name: (none) -> \"b\"", diff(nil, map[string]interface{}{"name": "b"})[0].String())
+}
+
+func TestState(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := LoadState(path)
+	assert.Nil(err)
+	assert.Equal(0, len(state.Resources))
+
+	state.Resources["dashboard.sales"] = Resource{ID: 10, Slug: "sales"}
+	state.Resources["query.sales.revenue"] = Resource{ID: 20}
+	assert.Nil(state.Save(path))
+
+	loaded, err := LoadState(path)
+	assert.Nil(err)
+	assert.Equal(state, loaded)
+	assert.Equal([]string{"dashboard.sales", "query.sales.revenue"}, loaded.Addresses())
+}
@@ -0,0 +1,86 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package reconcile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// StateVersion is the version of the state file format
+const StateVersion = 1
+
+// Resource is a Redash object managed by the reconciler
+type Resource struct {
+	ID   int    `json:"id"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// State maps the address of every managed object to its Redash ID
+type State struct {
+	Version   int                 `json:"version"`
+	Resources map[string]Resource `json:"resources"`
+}
+
+// NewState returns an empty *State
+func NewState() *State {
+	return &State{Version: StateVersion, Resources: map[string]Resource{}}
+}
+
+// LoadState reads a state file, a missing file is an empty state
+func LoadState(path string) (*State, error) {
+	body, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewState(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := NewState()
+	if err := json.Unmarshal(body, state); err != nil {
+		return nil, err
+	}
+	if state.Resources == nil {
+		state.Resources = map[string]Resource{}
+	}
+
+	return state, nil
+}
+
+// Save writes the state file, replacing it atomically
+func (s *State) Save(path string) error {
+	body, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(body, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Addresses returns every managed address in sorted order
+func (s *State) Addresses() []string {
+	addresses := make([]string, 0, len(s.Resources))
+	for address := range s.Resources {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
@@ -0,0 +1,80 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package recorder
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+)
+
+// FixtureVersion is the version of the fixture file format
+const FixtureVersion = 1
+
+// Fixture is a recorded sequence of interactions, stored as JSON such as redash/testdata/<name>.json
+type Fixture struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response it received
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded part of a request, without host, headers or api_key
+type Request struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  string          `json:"query,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Text   string          `json:"text,omitempty"`
+}
+
+// Response is the recorded part of a response. JSON bodies are kept in Body, others in Text.
+type Response struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	Text        string          `json:"text,omitempty"`
+}
+
+// LoadFixture reads a fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := &Fixture{}
+	if err := json.Unmarshal(body, fixture); err != nil {
+		return nil, err
+	}
+	return fixture, nil
+}
+
+// Save writes the fixture file, replacing it atomically
+func (f *Fixture) Save(path string) error {
+	body, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(body, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,248 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Package recorder records the HTTP interactions of a redash.Client into fixture files and
+// replays them, so tests run offline and deterministically:
+//
+//	r, err := recorder.New("testdata/get-query-42.json", recorder.ModeFromEnv())
+//	defer r.Save()
+//	c, err := redash.NewClient(&redash.Config{RedashURI: uri, APIKey: key, Transport: r})
+//
+// API keys and the secrets of JSON bodies, such as data source passwords, are scrubbed
+// before they are written. Requests are matched by method, path, query and body.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// Mode selects between recording and replaying
+type Mode int
+
+// Modes
+const (
+	// ModeReplay answers requests from the fixture and never sends them
+	ModeReplay Mode = iota
+	// ModeRecord sends requests and records them in the fixture
+	ModeRecord
+)
+
+// ModeFromEnv returns ModeRecord when REDASH_RECORD is set, ModeReplay otherwise
+func ModeFromEnv() Mode {
+	if os.Getenv("REDASH_RECORD") != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Recorder is an http.RoundTripper recording or replaying interactions
+type Recorder struct {
+	// Transport sends the requests while recording, http.DefaultTransport when nil
+	Transport http.RoundTripper
+
+	path    string
+	mode    Mode
+	mutex   sync.Mutex
+	fixture *Fixture
+	used    []bool
+}
+
+// New returns a *Recorder for a fixture file. Replaying reads the file, recording starts
+// an empty fixture which Save writes.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, fixture: &Fixture{Version: FixtureVersion}}
+	if mode == ModeReplay {
+		fixture, err := LoadFixture(path)
+		if err != nil {
+			return nil, err
+		}
+		if fixture.Version != FixtureVersion {
+			return nil, fmt.Errorf("%s: unsupported fixture version %d", path, fixture.Version)
+		}
+		r.fixture = fixture
+		r.used = make([]bool, len(fixture.Interactions))
+	}
+	return r, nil
+}
+
+// Fixture returns the recorded or replayed interactions
+func (r *Recorder) Fixture() *Fixture {
+	return r.fixture
+}
+
+// Save writes the fixture when recording, it does nothing when replaying
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.fixture.Save(r.path)
+}
+
+// RoundTrip records or replays a request
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	recorded, request, err := newRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		if request.Body != nil {
+			request.Body.Close()
+		}
+		return r.replay(request, recorded)
+	}
+	return r.record(request, recorded)
+}
+
+func (r *Recorder) record(request *http.Request, recorded Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{Request: recorded, Response: Response{
+		Status:      response.StatusCode,
+		ContentType: response.Header.Get("Content-Type"),
+	}}
+	interaction.Response.Body, interaction.Response.Text = scrubBody(body)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.fixture.Interactions = append(r.fixture.Interactions, interaction)
+	return response, nil
+}
+
+// replay answers with the first unused interaction matching the request
+func (r *Recorder) replay(request *http.Request, recorded Request) (*http.Response, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, interaction := range r.fixture.Interactions {
+		if r.used[i] || !recorded.matches(interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+		return interaction.Response.httpResponse(request), nil
+	}
+	return nil, fmt.Errorf("recorder: no interaction recorded in %s for %s %s", r.path, recorded.Method, recorded.Path)
+}
+
+// newRequest describes a request as it is stored in fixtures. The caller's request is never
+// modified: its body is read through GetBody when possible, otherwise the body is consumed
+// and a clone carrying a copy of it is returned for sending.
+func newRequest(request *http.Request) (Request, *http.Request, error) {
+	query := request.URL.Query()
+	query.Del("api_key")
+
+	recorded := Request{Method: request.Method, Path: request.URL.Path, Query: query.Encode()}
+	if request.Body == nil || request.Body == http.NoBody {
+		return recorded, request, nil
+	}
+
+	reader := request.Body
+	if request.GetBody != nil {
+		copied, err := request.GetBody()
+		if err != nil {
+			return recorded, request, err
+		}
+		reader = copied
+	}
+	body, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return recorded, request, err
+	}
+
+	if request.GetBody == nil {
+		request = request.Clone(request.Context())
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		request.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	recorded.Body, recorded.Text = scrubBody(body)
+	return recorded, request, nil
+}
+
+func (r Request) matches(other Request) bool {
+	return r.Method == other.Method && r.Path == other.Path && r.Query == other.Query &&
+		compact(r.Body) == compact(other.Body) && r.Text == other.Text
+}
+
+// compact returns a JSON body without the indentation of fixture files
+func compact(body json.RawMessage) string {
+	buffer := bytes.Buffer{}
+	if err := json.Compact(&buffer, body); err != nil {
+		return string(body)
+	}
+	return buffer.String()
+}
+
+func (r Response) httpResponse(request *http.Request) *http.Response {
+	body := r.Text
+	if len(r.Body) > 0 {
+		body = string(r.Body)
+	}
+
+	header := http.Header{}
+	if r.ContentType != "" {
+		header.Set("Content-Type", r.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}
+}
+
+// scrubBody returns a JSON body with its secrets redacted and its keys sorted, or the text of other bodies
+func scrubBody(body []byte) (json.RawMessage, string) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ""
+	}
+	if !json.Valid(body) {
+		return nil, string(body)
+	}
+	return json.RawMessage(redash.RedactJSON(string(body))), ""
+}
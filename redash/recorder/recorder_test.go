@@ -0,0 +1,155 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package recorder
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "fixture.json")
+
+	httpmock.Activate()
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/42",
+		httpmock.NewStringResponder(200, `{"id": 42, "name": "Revenue", "api_key": "QuErYkEy"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/data_sources",
+		httpmock.NewStringResponder(200, `{"id": 7, "name": "Warehouse", "type": "pg", "options": {"host": "db.acme", "password": "hunter2"}}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/43",
+		httpmock.NewStringResponder(404, `{"message": "Not found"}`))
+
+	r, err := New(path, ModeRecord)
+	assert.Nil(err)
+	r.Transport = httpmock.DefaultTransport
+
+	c, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy", Transport: r})
+	_, err = c.GetQuery(42)
+	assert.Nil(err)
+	_, err = c.CreateDataSource(&redash.DataSource{Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"host": "db.acme", "password": "hunter2"}})
+	assert.Nil(err)
+	_, err = c.GetQuery(43)
+	assert.NotNil(err)
+	assert.Nil(r.Save())
+
+	httpmock.DeactivateAndReset()
+
+	// Secrets never reach the fixture
+	body, err := ioutil.ReadFile(path)
+	assert.Nil(err)
+	for _, secret := range []string{"ApIkEy", "QuErYkEy", "hunter2"} {
+		assert.NotContains(string(body), secret)
+	}
+	assert.Len(r.Fixture().Interactions, 3)
+	assert.Equal("/api/queries/42", r.Fixture().Interactions[0].Request.Path)
+
+	// Replay answers without any network access
+	r, err = New(path, ModeReplay)
+	assert.Nil(err)
+	c, _ = redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "AnOtHeRkEy", Transport: r})
+
+	query, err := c.GetQuery(42)
+	assert.Nil(err)
+	assert.Equal("Revenue", query.Name)
+	assert.Equal(redash.RedactedValue, query.APIKey)
+
+	dataSource, err := c.CreateDataSource(&redash.DataSource{Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"host": "db.acme", "password": "other"}})
+	assert.Nil(err)
+	assert.Equal(7, dataSource.ID)
+
+	_, err = c.GetQuery(43)
+	assert.Contains(err.Error(), "404 from GET request")
+
+	// Every interaction is replayed once
+	_, err = c.GetQuery(42)
+	assert.Contains(err.Error(), "recorder: no interaction recorded")
+
+	_, err = c.CreateDataSource(&redash.DataSource{Name: "Lake", Type: "pg", Options: map[string]interface{}{"host": "db.acme"}})
+	assert.Contains(err.Error(), "recorder: no interaction recorded")
+}
+
+func TestModeFromEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("REDASH_RECORD", "")
+	assert.Equal(ModeReplay, ModeFromEnv())
+
+	t.Setenv("REDASH_RECORD", "1")
+	assert.Equal(ModeRecord, ModeFromEnv())
+
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	assert.NotNil(err)
+}
+
+func TestReplayFixture(t *testing.T) {
+	assert := assert.New(t)
+
+	r, err := New("testdata/get-query.json", ModeReplay)
+	assert.Nil(err)
+	c, _ := redash.NewClient(&redash.Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy", Transport: r})
+
+	queries, err := c.ListQueries(1, 25)
+	assert.Nil(err)
+	assert.Equal(1, queries.Count)
+
+	query, err := c.GetQuery(42)
+	assert.Nil(err)
+	assert.Equal("Revenue by country", query.Name)
+	assert.Equal([]string{"finance"}, query.Tags)
+
+	_, err = c.ListQueries(2, 25)
+	assert.NotNil(err)
+}
+
+func TestRecordLeavesRequestUntouched(t *testing.T) {
+	assert := assert.New(t)
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries",
+		httpmock.NewStringResponder(200, `{"id": 1}`))
+
+	r, err := New(filepath.Join(t.TempDir(), "fixture.json"), ModeRecord)
+	assert.Nil(err)
+	r.Transport = httpmock.DefaultTransport
+
+	// A body that can be read again through GetBody is never consumed
+	request, _ := http.NewRequest("POST", "https://com.acme/api/queries", strings.NewReader(`{"name": "Revenue"}`))
+	body := request.Body
+	_, err = r.RoundTrip(request)
+	assert.Nil(err)
+	assert.Equal(body, request.Body)
+	unread, _ := io.ReadAll(request.Body)
+	assert.Equal(`{"name": "Revenue"}`, string(unread))
+
+	// Any other body is sent from a clone of the request
+	request, _ = http.NewRequest("POST", "https://com.acme/api/queries", io.NopCloser(strings.NewReader(`{"name": "Sales"}`)))
+	body = request.Body
+	_, err = r.RoundTrip(request)
+	assert.Nil(err)
+	assert.Equal(body, request.Body)
+	assert.Nil(request.GetBody)
+
+	assert.Len(r.Fixture().Interactions, 2)
+	assert.JSONEq(`{"name": "Revenue"}`, string(r.Fixture().Interactions[0].Request.Body))
+	assert.JSONEq(`{"name": "Sales"}`, string(r.Fixture().Interactions[1].Request.Body))
+}
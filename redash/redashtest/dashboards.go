@@ -0,0 +1,286 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redashtest
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+type widget struct {
+	redash.WidgetDashboard
+	VisualizationID int
+}
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify derives a unique slug from a dashboard name like Redash, with a _N suffix on conflicts
+func (s *Server) slugify(name string, id int) string {
+	base := strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "dashboard"
+	}
+
+	slug := base
+	for n := 2; s.slugTaken(slug, id); n++ {
+		slug = base + "_" + strconv.Itoa(n)
+	}
+	return slug
+}
+
+func (s *Server) slugTaken(slug string, id int) bool {
+	for _, dashboard := range s.dashboards {
+		if dashboard.ID != id && dashboard.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// dashboard finds a dashboard by slug, or by ID as newer Redash versions allow
+func (s *Server) dashboard(arg string) (*redash.Dashboard, error) {
+	for _, dashboard := range s.dashboards {
+		if dashboard.Slug == arg {
+			return dashboard, nil
+		}
+	}
+	if dashboard, ok := s.dashboards[id(arg)]; ok {
+		return dashboard, nil
+	}
+	return nil, notFound("Dashboard", arg)
+}
+
+// dashboardResponse returns a dashboard with its widgets, their visualizations and queries
+func (s *Server) dashboardResponse(dashboard *redash.Dashboard) *redash.Dashboard {
+	response := *dashboard
+	response.Widgets = []redash.WidgetDashboard{}
+	for _, id := range sortedIDs(s.widgets) {
+		if w := s.widgets[id]; w.DashboardID == dashboard.ID {
+			response.Widgets = append(response.Widgets, s.widgetResponse(w))
+		}
+	}
+	return &response
+}
+
+func (s *Server) widgetResponse(w *widget) redash.WidgetDashboard {
+	response := w.WidgetDashboard
+	if v, ok := s.visualizations[w.VisualizationID]; ok {
+		convert(v.VisualizationQuery, &response.Visualization)
+		convert(s.queries[v.QueryID], &response.Visualization.Query)
+	}
+	return response
+}
+
+// getDashboards lists the dashboards that are not archived
+func (s *Server) getDashboards(r *http.Request, args []string) (interface{}, error) {
+	dashboards := []redash.DashboardListItem{}
+	for _, id := range sortedIDs(s.dashboards) {
+		if dashboard := s.dashboards[id]; !dashboard.IsArchived {
+			item := redash.DashboardListItem{}
+			convert(dashboard, &item)
+			dashboards = append(dashboards, item)
+		}
+	}
+
+	number, size, start, end := page(r, len(dashboards))
+	return list{Count: len(dashboards), Page: number, PageSize: size, Results: dashboards[start:end]}, nil
+}
+
+func (s *Server) getDashboard(r *http.Request, args []string) (interface{}, error) {
+	dashboard, err := s.dashboard(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return s.dashboardResponse(dashboard), nil
+}
+
+func (s *Server) createDashboard(r *http.Request, args []string) (interface{}, error) {
+	payload := redash.DashboardCreatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Name == "" {
+		return nil, errorf(http.StatusBadRequest, "name is required")
+	}
+
+	now := time.Now().UTC()
+	owner := *s.users[AdminUserID]
+	owner.APIKey = ""
+	dashboard := &redash.Dashboard{
+		ID:      s.nextID("dashboard"),
+		Name:    payload.Name,
+		Layout:  []interface{}{},
+		IsDraft: true,
+		Version: 1,
+		UserID:  owner.ID,
+		User:    owner,
+		Tags:    tags(payload.Tags),
+		CanEdit: true,
+
+		DashboardFiltersEnabled: payload.DashboardFiltersEnabled,
+
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	dashboard.Slug = s.slugify(payload.Name, dashboard.ID)
+	s.dashboards[dashboard.ID] = dashboard
+	return s.dashboardResponse(dashboard), nil
+}
+
+func (s *Server) updateDashboard(r *http.Request, args []string) (interface{}, error) {
+	dashboard, ok := s.dashboards[id(args[0])]
+	if !ok {
+		return nil, notFound("Dashboard", args[0])
+	}
+
+	payload := redash.DashboardUpdatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+
+	if payload.Name != "" && payload.Name != dashboard.Name {
+		dashboard.Name = payload.Name
+		dashboard.Slug = s.slugify(payload.Name, dashboard.ID)
+	}
+	if payload.Slug != "" && payload.Slug != dashboard.Slug {
+		if s.slugTaken(payload.Slug, dashboard.ID) {
+			return nil, errorf(http.StatusBadRequest, "Slug %s is already taken.", payload.Slug)
+		}
+		dashboard.Slug = payload.Slug
+	}
+	dashboard.IsDraft = payload.IsDraft
+	dashboard.IsArchived = payload.IsArchived
+	dashboard.DashboardFiltersEnabled = payload.DashboardFiltersEnabled
+	dashboard.Tags = tags(payload.Tags)
+	dashboard.Version++
+	dashboard.UpdatedAt = time.Now().UTC()
+	return s.dashboardResponse(dashboard), nil
+}
+
+func (s *Server) archiveDashboard(r *http.Request, args []string) (interface{}, error) {
+	dashboard, err := s.dashboard(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard.IsArchived = true
+	return s.dashboardResponse(dashboard), nil
+}
+
+func (s *Server) shareDashboard(r *http.Request, args []string) (interface{}, error) {
+	dashboard, ok := s.dashboards[id(args[0])]
+	if !ok {
+		return nil, notFound("Dashboard", args[0])
+	}
+
+	if dashboard.APIKey == "" {
+		dashboard.APIKey = s.newKey()
+	}
+	dashboard.PublicUrl = s.URL + "/public/dashboards/" + dashboard.APIKey + "?org_slug=default"
+	return redash.DashboardShare{PublicURL: dashboard.PublicUrl, APIKey: dashboard.APIKey}, nil
+}
+
+func (s *Server) unshareDashboard(r *http.Request, args []string) (interface{}, error) {
+	dashboard, ok := s.dashboards[id(args[0])]
+	if !ok {
+		return nil, notFound("Dashboard", args[0])
+	}
+
+	dashboard.PublicUrl, dashboard.APIKey = "", ""
+	return nil, nil
+}
+
+func (s *Server) createWidget(r *http.Request, args []string) (interface{}, error) {
+	payload := redash.WidgetCreatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if _, ok := s.dashboards[payload.DashboardID]; !ok {
+		return nil, notFound("Dashboard", payload.DashboardID)
+	}
+	if err := s.validateWidget(payload.VisualizationID, payload.Text); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	w := &widget{}
+	w.ID = s.nextID("widget")
+	w.DashboardID = payload.DashboardID
+	w.Text, w.Width, w.Options = payload.Text, payload.Width, payload.Options
+	if payload.VisualizationID != nil {
+		w.VisualizationID = *payload.VisualizationID
+	}
+	w.CreatedAt, w.UpdatedAt = now, now
+	s.widgets[w.ID] = w
+	return s.widgetResponse(w), nil
+}
+
+func (s *Server) validateWidget(visualizationID *int, text string) error {
+	if visualizationID == nil {
+		if text == "" {
+			return errorf(http.StatusBadRequest, "A widget needs a visualization or a text")
+		}
+		return nil
+	}
+	if _, ok := s.visualizations[*visualizationID]; !ok {
+		return notFound("Visualization", *visualizationID)
+	}
+	return nil
+}
+
+func (s *Server) updateWidget(r *http.Request, args []string) (interface{}, error) {
+	w, ok := s.widgets[id(args[0])]
+	if !ok {
+		return nil, notFound("Widget", args[0])
+	}
+
+	payload := redash.WidgetUpdatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if payload.VisualizationID != nil || w.VisualizationID == 0 {
+		if err := s.validateWidget(payload.VisualizationID, payload.Text); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Text, w.Width, w.Options = payload.Text, payload.Width, payload.Options
+	if payload.VisualizationID != nil {
+		w.VisualizationID = *payload.VisualizationID
+	}
+	w.UpdatedAt = time.Now().UTC()
+	return s.widgetResponse(w), nil
+}
+
+func (s *Server) deleteWidget(r *http.Request, args []string) (interface{}, error) {
+	if _, ok := s.widgets[id(args[0])]; !ok {
+		return nil, notFound("Widget", args[0])
+	}
+
+	delete(s.widgets, id(args[0]))
+	return nil, nil
+}
+
+func (s *Server) deleteWidgetsOf(visualizationID int) {
+	for id, w := range s.widgets {
+		if w.VisualizationID == visualizationID {
+			delete(s.widgets, id)
+		}
+	}
+}
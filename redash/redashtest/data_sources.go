@@ -0,0 +1,241 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redashtest
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// maskedSecret replaces the secret options of data sources in responses
+const maskedSecret = "--------"
+
+// dataSourceTypes are the types the fake accepts, with the configuration schema of Redash
+var dataSourceTypes = []redash.DataSourceType{
+	newDataSourceType("pg", "PostgreSQL", []string{"dbname"}, []string{"password"}, map[string]string{
+		"host": "string", "port": "number", "user": "string", "password": "string", "dbname": "string", "sslmode": "string",
+	}),
+	newDataSourceType("mysql", "MySQL", []string{"db"}, []string{"passwd"}, map[string]string{
+		"host": "string", "port": "number", "user": "string", "passwd": "string", "db": "string", "use_ssl": "boolean",
+	}),
+	newDataSourceType("results", "Query Results", nil, nil, map[string]string{}),
+}
+
+func newDataSourceType(name, title string, required, secret []string, properties map[string]string) redash.DataSourceType {
+	dataSourceType := redash.DataSourceType{Type: name, Name: title}
+	dataSourceType.ConfigurationSchema.Type = "object"
+	dataSourceType.ConfigurationSchema.Required = required
+	dataSourceType.ConfigurationSchema.Secret = secret
+	dataSourceType.ConfigurationSchema.Properties = map[string]redash.DataSourceTypePropertyField{}
+	for property, kind := range properties {
+		dataSourceType.ConfigurationSchema.Properties[property] = redash.DataSourceTypePropertyField{Type: kind, Title: property}
+		dataSourceType.ConfigurationSchema.Order = append(dataSourceType.ConfigurationSchema.Order, property)
+	}
+	sort.Strings(dataSourceType.ConfigurationSchema.Order)
+	return dataSourceType
+}
+
+func findDataSourceType(name string) *redash.DataSourceType {
+	for i := range dataSourceTypes {
+		if dataSourceTypes[i].Type == name {
+			return &dataSourceTypes[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) dataSource(arg string) (*redash.DataSource, error) {
+	dataSource, ok := s.dataSources[id(arg)]
+	if !ok {
+		return nil, notFound("Data source", arg)
+	}
+	return dataSource, nil
+}
+
+// masked returns a data source as Redash answers it, with secrets masked
+func masked(dataSource *redash.DataSource) *redash.DataSource {
+	copy := *dataSource
+	copy.Options = map[string]interface{}{}
+	copy.Groups = map[int]bool{}
+	for key, value := range dataSource.Options {
+		copy.Options[key] = value
+	}
+	for group, viewOnly := range dataSource.Groups {
+		copy.Groups[group] = viewOnly
+	}
+
+	if dataSourceType := findDataSourceType(dataSource.Type); dataSourceType != nil {
+		for _, secret := range dataSourceType.ConfigurationSchema.Secret {
+			if _, ok := copy.Options[secret]; ok {
+				copy.Options[secret] = maskedSecret
+			}
+		}
+	}
+	return &copy
+}
+
+func (s *Server) getDataSources(r *http.Request, args []string) (interface{}, error) {
+	dataSources := []redash.DataSource{}
+	for _, id := range sortedIDs(s.dataSources) {
+		dataSource := *s.dataSources[id]
+		dataSource.Options, dataSource.Groups = nil, nil
+		dataSources = append(dataSources, dataSource)
+	}
+	return dataSources, nil
+}
+
+func (s *Server) getDataSourceTypes(r *http.Request, args []string) (interface{}, error) {
+	return dataSourceTypes, nil
+}
+
+func (s *Server) getDataSource(r *http.Request, args []string) (interface{}, error) {
+	dataSource, err := s.dataSource(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return masked(dataSource), nil
+}
+
+func (s *Server) createDataSource(r *http.Request, args []string) (interface{}, error) {
+	payload := redash.DataSource{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if err := s.validateDataSource(0, &payload); err != nil {
+		return nil, err
+	}
+
+	dataSource := &redash.DataSource{
+		ID:      s.nextID("data_source"),
+		Name:    payload.Name,
+		Type:    payload.Type,
+		Syntax:  "sql",
+		Options: payload.Options,
+		Groups:  map[int]bool{DefaultGroupID: false},
+	}
+	if dataSource.Options == nil {
+		dataSource.Options = map[string]interface{}{}
+	}
+	s.dataSources[dataSource.ID] = dataSource
+	return masked(dataSource), nil
+}
+
+func (s *Server) validateDataSource(id int, payload *redash.DataSource) error {
+	if payload.Name == "" || payload.Type == "" {
+		return errorf(http.StatusBadRequest, "name and type are required")
+	}
+	dataSourceType := findDataSourceType(payload.Type)
+	if dataSourceType == nil {
+		return errorf(http.StatusBadRequest, "Unsupported data source type: %s", payload.Type)
+	}
+	for _, required := range dataSourceType.ConfigurationSchema.Required {
+		if _, ok := payload.Options[required]; !ok {
+			return errorf(http.StatusBadRequest, "Missing required option: %s", required)
+		}
+	}
+	for _, other := range s.dataSources {
+		if other.ID != id && other.Name == payload.Name {
+			return errorf(http.StatusBadRequest, "Data source with the name %s already exists.", payload.Name)
+		}
+	}
+	return nil
+}
+
+func (s *Server) updateDataSource(r *http.Request, args []string) (interface{}, error) {
+	dataSource, err := s.dataSource(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	payload := redash.DataSource{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if err := s.validateDataSource(dataSource.ID, &payload); err != nil {
+		return nil, err
+	}
+
+	// Masked secrets keep their current value, as in Redash
+	options := map[string]interface{}{}
+	for key, value := range payload.Options {
+		if value == maskedSecret {
+			value = dataSource.Options[key]
+		}
+		options[key] = value
+	}
+
+	dataSource.Name, dataSource.Type, dataSource.Options = payload.Name, payload.Type, options
+	return masked(dataSource), nil
+}
+
+// deleteDataSource deletes the queries of the data source with it, as Redash does
+func (s *Server) deleteDataSource(r *http.Request, args []string) (interface{}, error) {
+	dataSource, err := s.dataSource(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, query := range s.queries {
+		if query.DataSourceID == dataSource.ID {
+			s.deleteQuery(query.ID)
+		}
+	}
+	delete(s.dataSources, dataSource.ID)
+	return nil, nil
+}
+
+func (s *Server) testDataSource(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.dataSource(args[0]); err != nil {
+		return nil, err
+	}
+	return redash.DataSourceTestResult{Message: "success", Ok: true}, nil
+}
+
+func sortedIDs(objects interface{}) []int {
+	ids := []int{}
+	switch objects := objects.(type) {
+	case map[int]*redash.DataSource:
+		for id := range objects {
+			ids = append(ids, id)
+		}
+	case map[int]*redash.Group:
+		for id := range objects {
+			ids = append(ids, id)
+		}
+	case map[int]*redash.User:
+		for id := range objects {
+			ids = append(ids, id)
+		}
+	case map[int]*redash.Query:
+		for id := range objects {
+			ids = append(ids, id)
+		}
+	case map[int]*visualization:
+		for id := range objects {
+			ids = append(ids, id)
+		}
+	case map[int]*redash.Dashboard:
+		for id := range objects {
+			ids = append(ids, id)
+		}
+	case map[int]*widget:
+		for id := range objects {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -0,0 +1,179 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redashtest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// defaultPermissions are the permissions of the default group and of new groups
+var defaultPermissions = []string{
+	"create_dashboard", "create_query", "edit_dashboard", "edit_query", "view_query", "view_source",
+	"execute_query", "list_users", "schedule_query", "list_dashboards", "list_alerts", "list_data_sources",
+}
+
+func (s *Server) group(arg string) (*redash.Group, error) {
+	group, ok := s.groups[id(arg)]
+	if !ok {
+		return nil, notFound("Group", arg)
+	}
+	return group, nil
+}
+
+func (s *Server) getGroups(r *http.Request, args []string) (interface{}, error) {
+	groups := []redash.Group{}
+	for _, id := range sortedIDs(s.groups) {
+		groups = append(groups, *s.groups[id])
+	}
+	return groups, nil
+}
+
+func (s *Server) getGroup(r *http.Request, args []string) (interface{}, error) {
+	return s.group(args[0])
+}
+
+func (s *Server) createGroup(r *http.Request, args []string) (interface{}, error) {
+	payload := redash.GroupCreatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Name == "" {
+		return nil, errorf(http.StatusBadRequest, "name is required")
+	}
+
+	group := &redash.Group{
+		ID:          s.nextID("group"),
+		Name:        payload.Name,
+		Type:        "regular",
+		Permissions: defaultPermissions,
+		CreatedAt:   time.Now().UTC(),
+	}
+	s.groups[group.ID] = group
+	return group, nil
+}
+
+func (s *Server) updateGroup(r *http.Request, args []string) (interface{}, error) {
+	group, err := s.group(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	payload := redash.Group{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Name != "" {
+		group.Name = payload.Name
+	}
+	return group, nil
+}
+
+// deleteGroup removes the memberships and data source grants of the group with it
+func (s *Server) deleteGroup(r *http.Request, args []string) (interface{}, error) {
+	group, err := s.group(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if group.Type == "builtin" {
+		return nil, errorf(http.StatusBadRequest, "Can't delete built-in groups.")
+	}
+
+	for _, user := range s.users {
+		user.Groups = without(user.Groups, group.ID)
+	}
+	for _, dataSource := range s.dataSources {
+		delete(dataSource.Groups, group.ID)
+	}
+	delete(s.groups, group.ID)
+	return nil, nil
+}
+
+func (s *Server) addGroupMember(r *http.Request, args []string) (interface{}, error) {
+	group, err := s.group(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	payload := redash.GroupUser{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	user, ok := s.users[payload.MemberID]
+	if !ok {
+		return nil, notFound("User", payload.MemberID)
+	}
+
+	user.Groups = append(without(user.Groups, group.ID), group.ID)
+	return s.userListItem(user), nil
+}
+
+func (s *Server) removeGroupMember(r *http.Request, args []string) (interface{}, error) {
+	group, err := s.group(args[0])
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.user(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	user.Groups = without(user.Groups, group.ID)
+	return nil, nil
+}
+
+func (s *Server) addGroupDataSource(r *http.Request, args []string) (interface{}, error) {
+	group, err := s.group(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	payload := redash.GroupDataSource{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	dataSource, ok := s.dataSources[payload.DataSourceID]
+	if !ok {
+		return nil, notFound("Data source", payload.DataSourceID)
+	}
+
+	dataSource.Groups[group.ID] = false
+	return masked(dataSource), nil
+}
+
+func (s *Server) removeGroupDataSource(r *http.Request, args []string) (interface{}, error) {
+	group, err := s.group(args[0])
+	if err != nil {
+		return nil, err
+	}
+	dataSource, err := s.dataSource(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	delete(dataSource.Groups, group.ID)
+	return nil, nil
+}
+
+func without(ids []int, id int) []int {
+	result := []int{}
+	for _, other := range ids {
+		if other != id {
+			result = append(result, other)
+		}
+	}
+	return result
+}
@@ -0,0 +1,258 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redashtest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+type visualization struct {
+	redash.VisualizationQuery
+	QueryID int
+}
+
+func (s *Server) query(arg string) (*redash.Query, error) {
+	query, ok := s.queries[id(arg)]
+	if !ok {
+		return nil, notFound("Query", arg)
+	}
+	return query, nil
+}
+
+// queryResponse returns a query with its visualizations
+func (s *Server) queryResponse(query *redash.Query) *redash.Query {
+	response := *query
+	response.Visualizations = []redash.VisualizationQuery{}
+	for _, id := range sortedIDs(s.visualizations) {
+		if v := s.visualizations[id]; v.QueryID == query.ID {
+			response.Visualizations = append(response.Visualizations, v.VisualizationQuery)
+		}
+	}
+	return &response
+}
+
+// getQueries lists the queries that are not archived
+func (s *Server) getQueries(r *http.Request, args []string) (interface{}, error) {
+	queries := []redash.QueryListItem{}
+	for _, id := range sortedIDs(s.queries) {
+		if query := s.queries[id]; !query.IsArchived {
+			item := redash.QueryListItem{}
+			convert(query, &item)
+			queries = append(queries, item)
+		}
+	}
+
+	number, size, start, end := page(r, len(queries))
+	return list{Count: len(queries), Page: number, PageSize: size, Results: queries[start:end]}, nil
+}
+
+func (s *Server) getQuery(r *http.Request, args []string) (interface{}, error) {
+	query, err := s.query(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return s.queryResponse(query), nil
+}
+
+// createQuery creates the query and its default table visualization, as Redash does
+func (s *Server) createQuery(r *http.Request, args []string) (interface{}, error) {
+	payload := redash.QueryCreatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if err := s.validateQuery(payload.Name, payload.DataSourceID, payload.Options); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	admin := *s.users[AdminUserID]
+	admin.APIKey = ""
+	query := &redash.Query{
+		ID:             s.nextID("query"),
+		Name:           payload.Name,
+		Description:    payload.Description,
+		DataSourceID:   payload.DataSourceID,
+		Query:          payload.Query,
+		QueryHash:      queryHash(payload.Query),
+		Options:        payload.Options,
+		IsDraft:        payload.IsDraft,
+		Version:        1,
+		User:           admin,
+		LastModifiedBy: admin,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		APIKey:         s.newKey(),
+		Tags:           tags(payload.Tags),
+		CanEdit:        true,
+	}
+	if payload.Schedule != nil {
+		query.Schedule = *payload.Schedule
+	}
+	s.queries[query.ID] = query
+
+	table := &visualization{QueryID: query.ID}
+	table.ID = s.nextID("visualization")
+	table.Name = "Table"
+	table.Type = "TABLE"
+	table.Options = map[string]interface{}{}
+	table.CreatedAt, table.UpdatedAt = now, now
+	s.visualizations[table.ID] = table
+
+	return s.queryResponse(query), nil
+}
+
+func (s *Server) validateQuery(name string, dataSourceID int, options redash.QueryOptions) error {
+	if name == "" {
+		return errorf(http.StatusBadRequest, "name is required")
+	}
+	if _, ok := s.dataSources[dataSourceID]; !ok {
+		return notFound("Data source", dataSourceID)
+	}
+	for _, parameter := range options.Parameters {
+		if parameter.QueryID == 0 {
+			continue
+		}
+		if _, ok := s.queries[parameter.QueryID]; !ok {
+			return errorf(http.StatusBadRequest, "Query %d of parameter %s not found", parameter.QueryID, parameter.Name)
+		}
+	}
+	return nil
+}
+
+func (s *Server) updateQuery(r *http.Request, args []string) (interface{}, error) {
+	query, err := s.query(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	payload := redash.QueryUpdatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if err := s.validateQuery(payload.Name, payload.DataSourceID, payload.Options); err != nil {
+		return nil, err
+	}
+
+	query.Name = payload.Name
+	query.Description = payload.Description
+	query.DataSourceID = payload.DataSourceID
+	query.Query = payload.Query
+	query.QueryHash = queryHash(payload.Query)
+	query.Options = payload.Options
+	query.IsDraft = payload.IsDraft
+	query.Tags = tags(payload.Tags)
+	if payload.Schedule != nil {
+		query.Schedule = *payload.Schedule
+	}
+	query.Version++
+	query.UpdatedAt = time.Now().UTC()
+	return s.queryResponse(query), nil
+}
+
+// archiveQuery archives the query and removes the widgets of its visualizations, as Redash does
+func (s *Server) archiveQuery(r *http.Request, args []string) (interface{}, error) {
+	query, err := s.query(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	query.IsArchived = true
+	query.Schedule = redash.QuerySchedule{}
+	for _, v := range s.visualizations {
+		if v.QueryID == query.ID {
+			s.deleteWidgetsOf(v.ID)
+		}
+	}
+	return nil, nil
+}
+
+// deleteQuery deletes a query with its visualizations and their widgets
+func (s *Server) deleteQuery(id int) {
+	for _, v := range s.visualizations {
+		if v.QueryID == id {
+			s.deleteWidgetsOf(v.ID)
+			delete(s.visualizations, v.ID)
+		}
+	}
+	delete(s.queries, id)
+}
+
+func (s *Server) createVisualization(r *http.Request, args []string) (interface{}, error) {
+	payload := redash.VisualizationCreatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if _, ok := s.queries[payload.QueryId]; !ok {
+		return nil, notFound("Query", payload.QueryId)
+	}
+	if payload.Type == "" {
+		return nil, errorf(http.StatusBadRequest, "type is required")
+	}
+
+	now := time.Now().UTC()
+	v := &visualization{QueryID: payload.QueryId}
+	v.ID = s.nextID("visualization")
+	v.Name, v.Description, v.Type, v.Options = payload.Name, payload.Description, payload.Type, payload.Options
+	v.CreatedAt, v.UpdatedAt = now, now
+	s.visualizations[v.ID] = v
+	return v.VisualizationQuery, nil
+}
+
+func (s *Server) updateVisualization(r *http.Request, args []string) (interface{}, error) {
+	v, ok := s.visualizations[id(args[0])]
+	if !ok {
+		return nil, notFound("Visualization", args[0])
+	}
+
+	payload := redash.VisualizationUpdatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Type == "" {
+		return nil, errorf(http.StatusBadRequest, "type is required")
+	}
+
+	v.Name, v.Description, v.Type, v.Options = payload.Name, payload.Description, payload.Type, payload.Options
+	v.UpdatedAt = time.Now().UTC()
+	return v.VisualizationQuery, nil
+}
+
+// deleteVisualization deletes the widgets showing the visualization with it
+func (s *Server) deleteVisualization(r *http.Request, args []string) (interface{}, error) {
+	v, ok := s.visualizations[id(args[0])]
+	if !ok {
+		return nil, notFound("Visualization", args[0])
+	}
+
+	s.deleteWidgetsOf(v.ID)
+	delete(s.visualizations, v.ID)
+	return nil, nil
+}
+
+func queryHash(text string) string {
+	sum := sha1.Sum([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+func tags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
@@ -0,0 +1,84 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redashtest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+type queryResult struct {
+	redash.QueryResult
+	QueryID int
+}
+
+// AddQueryResult stores data as the latest result of a query, as if the query had run
+func (s *Server) AddQueryResult(queryID int, data redash.QueryResultData) (*redash.QueryResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	query, ok := s.queries[queryID]
+	if !ok {
+		return nil, notFound("Query", queryID)
+	}
+
+	result := &queryResult{QueryID: query.ID}
+	result.ID = s.nextID("query_result")
+	result.QueryHash = query.QueryHash
+	result.Query = query.Query
+	result.DataSourceID = query.DataSourceID
+	result.Data = data
+	result.RetrievedAt = time.Now().UTC()
+	s.results[result.ID] = result
+	query.LatestQueryDataID = result.ID
+
+	response := result.QueryResult
+	return &response, nil
+}
+
+func (s *Server) queryResult(arg string) (*queryResult, error) {
+	result, ok := s.results[id(arg)]
+	if !ok {
+		return nil, notFound("Query result", arg)
+	}
+	return result, nil
+}
+
+func (s *Server) getQueryResult(r *http.Request, args []string) (interface{}, error) {
+	result, err := s.queryResult(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"query_result": result.QueryResult}, nil
+}
+
+// getQueryResults answers the latest result of a query, or the given one when it belongs to the query
+func (s *Server) getQueryResults(r *http.Request, args []string) (interface{}, error) {
+	query, err := s.query(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	resultID := query.LatestQueryDataID
+	if len(args) > 1 && args[1] != "" {
+		resultID = id(args[1])
+	}
+	result, ok := s.results[resultID]
+	if !ok || result.QueryID != query.ID {
+		return nil, errorf(http.StatusNotFound, "No cached result found for this query.")
+	}
+	return map[string]interface{}{"query_result": result.QueryResult}, nil
+}
@@ -0,0 +1,332 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+// Package redashtest provides an in-memory fake of the Redash API for end-to-end tests
+// of code built on redash.Client:
+//
+//	server := redashtest.NewServer()
+//	defer server.Close()
+//	client := server.Client()
+//
+// The fake hands out IDs, enforces references between objects and answers errors the
+// way Redash does, with a JSON {"message": ...} body. It covers data sources, groups,
+// users, queries, visualizations, widgets and dashboards. Query execution is not supported,
+// results are stored with AddQueryResult and read with a user or the query's own API key.
+package redashtest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+// IDs of the objects every server starts with
+const (
+	AdminGroupID   = 1
+	DefaultGroupID = 2
+	AdminUserID    = 1
+)
+
+// Error is an error answered by the fake, written as {"message": Message} with Status
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func errorf(status int, format string, args ...interface{}) *Error {
+	return &Error{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+func notFound(kind string, id interface{}) *Error {
+	return errorf(http.StatusNotFound, "%s %v not found", kind, id)
+}
+
+// Server is a stateful fake Redash instance backed by an httptest.Server
+type Server struct {
+	*httptest.Server
+
+	// APIKey is the key of the admin user, used by Client
+	APIKey string
+
+	mutex    sync.Mutex
+	lastID   map[string]int
+	keys     int
+	handlers []route
+
+	dataSources    map[int]*redash.DataSource
+	groups         map[int]*redash.Group
+	users          map[int]*redash.User
+	queries        map[int]*redash.Query
+	visualizations map[int]*visualization
+	dashboards     map[int]*redash.Dashboard
+	widgets        map[int]*widget
+	results        map[int]*queryResult
+}
+
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handle  func(r *http.Request, args []string) (interface{}, error)
+}
+
+// NewServer starts a fake with the builtin admin and default groups and an admin user
+func NewServer() *Server {
+	s := &Server{
+		lastID:         map[string]int{},
+		dataSources:    map[int]*redash.DataSource{},
+		groups:         map[int]*redash.Group{},
+		users:          map[int]*redash.User{},
+		queries:        map[int]*redash.Query{},
+		visualizations: map[int]*visualization{},
+		dashboards:     map[int]*redash.Dashboard{},
+		widgets:        map[int]*widget{},
+		results:        map[int]*queryResult{},
+	}
+	s.routes()
+
+	now := time.Now().UTC()
+	s.groups[s.nextID("group")] = &redash.Group{ID: AdminGroupID, Name: "admin", Type: "builtin", Permissions: []string{"admin", "super_admin"}, CreatedAt: now}
+	s.groups[s.nextID("group")] = &redash.Group{ID: DefaultGroupID, Name: "default", Type: "builtin", Permissions: defaultPermissions, CreatedAt: now}
+
+	admin := &redash.User{ID: s.nextID("user"), Name: "Admin", Email: "admin@example.com", AuthType: "password", Groups: []int{AdminGroupID, DefaultGroupID}, CreatedAt: now, UpdatedAt: now, IsEmailVerified: true}
+	admin.APIKey = s.newKey()
+	s.users[admin.ID] = admin
+	s.APIKey = admin.APIKey
+
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Client returns a client of the fake authenticated as the admin user
+func (s *Server) Client() *redash.Client {
+	c, err := redash.NewClient(&redash.Config{RedashURI: s.URL, APIKey: s.APIKey})
+	if err != nil {
+		panic(err.Error())
+	}
+	return c
+}
+
+func (s *Server) nextID(kind string) int {
+	s.lastID[kind]++
+	return s.lastID[kind]
+}
+
+func (s *Server) newKey() string {
+	s.keys++
+	sum := sha1.Sum([]byte("redashtest-" + strconv.Itoa(s.keys)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Server) handle(method, pattern string, handle func(r *http.Request, args []string) (interface{}, error)) {
+	s.handlers = append(s.handlers, route{method, regexp.MustCompile("^" + pattern + "$"), handle})
+}
+
+func (s *Server) routes() {
+	s.handle("GET", "/api/data_sources", s.getDataSources)
+	s.handle("POST", "/api/data_sources", s.createDataSource)
+	s.handle("GET", "/api/data_sources/types", s.getDataSourceTypes)
+	s.handle("GET", `/api/data_sources/(\d+)`, s.getDataSource)
+	s.handle("POST", `/api/data_sources/(\d+)`, s.updateDataSource)
+	s.handle("DELETE", `/api/data_sources/(\d+)`, s.deleteDataSource)
+	s.handle("POST", `/api/data_sources/(\d+)/test`, s.testDataSource)
+
+	s.handle("GET", "/api/groups", s.getGroups)
+	s.handle("POST", "/api/groups", s.createGroup)
+	s.handle("GET", `/api/groups/(\d+)`, s.getGroup)
+	s.handle("POST", `/api/groups/(\d+)`, s.updateGroup)
+	s.handle("DELETE", `/api/groups/(\d+)`, s.deleteGroup)
+	s.handle("POST", `/api/groups/(\d+)/members`, s.addGroupMember)
+	s.handle("DELETE", `/api/groups/(\d+)/members/(\d+)`, s.removeGroupMember)
+	s.handle("POST", `/api/groups/(\d+)/data_sources`, s.addGroupDataSource)
+	s.handle("DELETE", `/api/groups/(\d+)/data_sources/(\d+)`, s.removeGroupDataSource)
+
+	s.handle("GET", "/api/users", s.getUsers)
+	s.handle("POST", "/api/users", s.createUser)
+	s.handle("GET", `/api/users/(\d+)`, s.getUser)
+	s.handle("POST", `/api/users/(\d+)`, s.updateUser)
+	s.handle("POST", `/api/users/(\d+)/disable`, s.disableUser)
+	s.handle("DELETE", `/api/users/(\d+)/disable`, s.enableUser)
+	s.handle("POST", `/api/users/(\d+)/invite`, s.inviteUser)
+	s.handle("POST", `/api/users/(\d+)/reset_password`, s.resetPassword)
+	s.handle("POST", `/api/users/(\d+)/regenerate_api_key`, s.regenerateAPIKey)
+
+	s.handle("GET", "/api/queries", s.getQueries)
+	s.handle("POST", "/api/queries", s.createQuery)
+	s.handle("GET", `/api/queries/(\d+)`, s.getQuery)
+	s.handle("POST", `/api/queries/(\d+)`, s.updateQuery)
+	s.handle("DELETE", `/api/queries/(\d+)`, s.archiveQuery)
+	s.handle("GET", `/api/queries/(\d+)/results(?:/(\d+))?(?:\.json)?`, s.getQueryResults)
+	s.handle("GET", `/api/query_results/(\d+)`, s.getQueryResult)
+
+	s.handle("POST", "/api/visualizations", s.createVisualization)
+	s.handle("POST", `/api/visualizations/(\d+)`, s.updateVisualization)
+	s.handle("DELETE", `/api/visualizations/(\d+)`, s.deleteVisualization)
+
+	s.handle("GET", "/api/dashboards", s.getDashboards)
+	s.handle("POST", "/api/dashboards", s.createDashboard)
+	s.handle("GET", `/api/dashboards/([^/]+)`, s.getDashboard)
+	s.handle("POST", `/api/dashboards/(\d+)`, s.updateDashboard)
+	s.handle("DELETE", `/api/dashboards/([^/]+)`, s.archiveDashboard)
+	s.handle("POST", `/api/dashboards/(\d+)/share`, s.shareDashboard)
+	s.handle("DELETE", `/api/dashboards/(\d+)/share`, s.unshareDashboard)
+
+	s.handle("POST", "/api/widgets", s.createWidget)
+	s.handle("POST", `/api/widgets/(\d+)`, s.updateWidget)
+	s.handle("DELETE", `/api/widgets/(\d+)`, s.deleteWidget)
+}
+
+// ServeHTTP answers a request, an organization prefix such as /acme/api/... is ignored
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := r.URL.Path
+	if i := strings.Index(path, "/api/"); i > 0 {
+		path = path[i:]
+	}
+
+	if !s.authenticated(r, path) {
+		// Redash answers unauthenticated API calls with a 404
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Couldn't find resource. Please login and try again."})
+		return
+	}
+
+	for _, route := range s.handlers {
+		match := route.pattern.FindStringSubmatch(path)
+		if match == nil || route.method != r.Method {
+			continue
+		}
+
+		body, err := route.handle(r, match[1:])
+		if err != nil {
+			status := http.StatusInternalServerError
+			if e, ok := err.(*Error); ok {
+				status = e.Status
+			}
+			writeJSON(w, status, map[string]string{"message": err.Error()})
+			return
+		}
+		if body == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, http.StatusOK, body)
+		return
+	}
+
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "The requested URL was not found on the server."})
+}
+
+// queryKeyPaths are the endpoints a query's own API key can read, the results of that query
+var queryKeyPaths = regexp.MustCompile(`^/api/(?:queries/(\d+)/results(?:/\d+)?(?:\.json)?|query_results/(\d+))$`)
+
+// authenticated accepts the key of an enabled user on any endpoint and the key of a query on
+// the results of that query only, like Redash
+func (s *Server) authenticated(r *http.Request, path string) bool {
+	key := r.URL.Query().Get("api_key")
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Key ") {
+		key = strings.TrimPrefix(header, "Key ")
+	}
+	if key == "" {
+		return false
+	}
+
+	for _, user := range s.users {
+		if user.APIKey == key && !user.IsDisabled {
+			return true
+		}
+	}
+
+	match := queryKeyPaths.FindStringSubmatch(path)
+	if match == nil || r.Method != http.MethodGet {
+		return false
+	}
+	queryID := id(match[1])
+	if result, ok := s.results[id(match[2])]; ok {
+		queryID = result.QueryID
+	}
+	query, ok := s.queries[queryID]
+	return ok && !query.IsArchived && query.APIKey == key
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func decode(r *http.Request, payload interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return errorf(http.StatusBadRequest, "invalid JSON payload: %s", err)
+	}
+	return nil
+}
+
+// convert copies an object into another shape, such as a Query into a QueryListItem
+func convert(from, to interface{}) {
+	body, err := json.Marshal(from)
+	if err != nil {
+		panic(err.Error())
+	}
+	if err := json.Unmarshal(body, to); err != nil {
+		panic(err.Error())
+	}
+}
+
+func id(arg string) int {
+	id, _ := strconv.Atoi(arg)
+	return id
+}
+
+// list is the paginated list of Redash
+type list struct {
+	Count    int         `json:"count"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Results  interface{} `json:"results"`
+}
+
+// page returns the bounds of a page of n items from the page and page_size parameters
+func page(r *http.Request, n int) (int, int, int, int) {
+	number, size := 1, 25
+	if value, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && value > 0 {
+		number = value
+	}
+	if value, err := strconv.Atoi(r.URL.Query().Get("page_size")); err == nil && value > 0 {
+		size = value
+	}
+
+	start := (number - 1) * size
+	if start > n {
+		start = n
+	}
+	end := start + size
+	if end > n {
+		end = n
+	}
+	return number, size, start, end
+}
@@ -0,0 +1,224 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redashtest
+
+import (
+	"testing"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataSourcesAndGroups(t *testing.T) {
+	assert := assert.New(t)
+	server := NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	dataSource, err := c.CreateDataSource(&redash.DataSource{Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"dbname": "dwh", "password": "hunter2"}})
+	assert.Nil(err)
+	assert.Equal(1, dataSource.ID)
+	assert.Equal(maskedSecret, dataSource.Options["password"])
+
+	_, err = c.CreateDataSource(&redash.DataSource{Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"dbname": "dwh"}})
+	assert.Contains(err.Error(), "400 from POST request")
+	assert.Contains(err.Error(), `{"message":"Data source with the name Warehouse already exists."}`)
+
+	group, err := c.CreateGroup(&redash.GroupCreatePayload{Name: "analysts"})
+	assert.Nil(err)
+	assert.Equal(3, group.ID)
+	assert.Nil(c.GroupAddDataSource(group.ID, dataSource.ID))
+
+	dataSource, err = c.GetDataSource(dataSource.ID)
+	assert.Nil(err)
+	assert.Equal(map[int]bool{DefaultGroupID: false, group.ID: false}, dataSource.Groups)
+
+	// Masked secrets are kept on update
+	dataSource.Options["host"] = "db.acme"
+	_, err = c.UpdateDataSource(dataSource.ID, &redash.DataSource{Name: "Warehouse", Type: "pg", Options: dataSource.Options})
+	assert.Nil(err)
+	assert.Equal("hunter2", server.dataSources[dataSource.ID].Options["password"])
+
+	assert.NotNil(c.GroupAddDataSource(group.ID, 99))
+	assert.NotNil(c.DeleteGroup(DefaultGroupID))
+	assert.Nil(c.DeleteGroup(group.ID))
+
+	dataSource, _ = c.GetDataSource(dataSource.ID)
+	assert.Equal(map[int]bool{DefaultGroupID: false}, dataSource.Groups)
+
+	result, err := c.TestDataSource(dataSource.ID)
+	assert.Nil(err)
+	assert.True(result.Ok)
+}
+
+func TestUsers(t *testing.T) {
+	assert := assert.New(t)
+	server := NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	user, err := c.CreateUser(&redash.UserCreatePayload{Name: "Ada", Email: "ada@acme.com"})
+	assert.Nil(err)
+	assert.Equal(2, user.ID)
+	assert.True(user.IsInvitationPending)
+	assert.NotEmpty(user.InviteLink)
+
+	_, err = c.CreateUser(&redash.UserCreatePayload{Name: "Ada", Email: "ADA@acme.com"})
+	assert.Contains(err.Error(), "Email already taken.")
+
+	assert.Nil(c.GroupAddUser(AdminGroupID, user.ID))
+	assert.NotNil(c.GroupAddUser(AdminGroupID, 99))
+
+	users, err := c.SearchUsers("ada")
+	assert.Nil(err)
+	assert.Equal(1, users.Count)
+	assert.Equal([]redash.UserListGroup{{ID: DefaultGroupID, Name: "default"}, {ID: AdminGroupID, Name: "admin"}}, users.Results[0].Groups)
+
+	assert.Nil(c.DisableUser(user.ID))
+	users, _ = c.GetUsers(1, 25)
+	assert.Equal(1, users.Count)
+	isDisabled := true
+	users, _ = c.GetUsers(1, 25, &redash.UserListOptions{Disabled: &isDisabled})
+	assert.Equal(1, users.Count)
+
+	// Disabled users can't authenticate
+	disabled, _ := redash.NewClient(&redash.Config{RedashURI: server.URL, APIKey: user.APIKey})
+	_, err = disabled.GetGroups()
+	assert.Contains(err.Error(), "404 from GET request")
+
+	user, err = c.EnableUser(user.ID)
+	assert.Nil(err)
+	assert.False(user.IsDisabled)
+	_, err = disabled.GetGroups()
+	assert.Nil(err)
+
+	user, err = c.RegenerateUserAPIKey(user.ID)
+	assert.Nil(err)
+	_, err = disabled.GetGroups()
+	assert.NotNil(err)
+
+	_, err = c.UpdateUser(user.ID, &redash.UserUpdatePayload{Name: "Ada Lovelace", Email: "ada@acme.com", Groups: []int{DefaultGroupID, 42}})
+	assert.Contains(err.Error(), "Group 42 not found")
+}
+
+func TestQueriesAndDashboards(t *testing.T) {
+	assert := assert.New(t)
+	server := NewServer()
+	defer server.Close()
+	c := server.Client().WithOrg("acme")
+
+	_, err := c.CreateQuery(&redash.QueryCreatePayload{Name: "Revenue", DataSourceID: 1, Query: "SELECT 1"})
+	assert.Contains(err.Error(), "404 from POST request")
+
+	dataSource, _ := c.CreateDataSource(&redash.DataSource{Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"dbname": "dwh"}})
+	query, err := c.CreateQuery(&redash.QueryCreatePayload{Name: "Revenue", DataSourceID: dataSource.ID, Query: "SELECT 1", Tags: []string{"finance"}})
+	assert.Nil(err)
+	assert.Equal(1, query.Version)
+	assert.Len(query.Visualizations, 1)
+	assert.Equal("TABLE", query.Visualizations[0].Type)
+
+	query, err = c.UpdateQuery(query.ID, &redash.QueryUpdatePayload{Name: "Revenue by day", DataSourceID: dataSource.ID, Query: "SELECT 2"})
+	assert.Nil(err)
+	assert.Equal(2, query.Version)
+
+	chart, err := c.CreateVisualization(&redash.VisualizationCreatePayload{Name: "Chart", Type: "CHART", QueryId: query.ID, Options: map[string]interface{}{"globalSeriesType": "line"}})
+	assert.Nil(err)
+	_, err = c.CreateVisualization(&redash.VisualizationCreatePayload{Name: "Chart", Type: "CHART", QueryId: 99})
+	assert.NotNil(err)
+
+	dashboard, err := c.CreateDashboard(&redash.DashboardCreatePayload{Name: "Service SLOs"})
+	assert.Nil(err)
+	assert.Equal("service-slos", dashboard.Slug)
+	other, _ := c.CreateDashboard(&redash.DashboardCreatePayload{Name: "Service SLOs"})
+	assert.Equal("service-slos_2", other.Slug)
+
+	widget, err := c.CreateWidget(&redash.WidgetCreatePayload{DashboardID: dashboard.ID, VisualizationID: &chart.ID, Width: 1})
+	assert.Nil(err)
+	assert.Equal(chart.ID, widget.Visualization.ID)
+	assert.Equal("Revenue by day", widget.Visualization.Query.Name)
+	_, err = c.CreateTextWidget(dashboard.ID, "## Revenue", redash.WidgetPosition{SizeX: 6, SizeY: 2})
+	assert.Nil(err)
+
+	missing := 99
+	_, err = c.CreateWidget(&redash.WidgetCreatePayload{DashboardID: dashboard.ID, VisualizationID: &missing})
+	assert.NotNil(err)
+
+	dashboard, err = c.GetDashboard("service-slos")
+	assert.Nil(err)
+	assert.Len(dashboard.Widgets, 2)
+
+	share, err := c.ShareDashboard(dashboard.ID)
+	assert.Nil(err)
+	assert.Contains(share.PublicURL, share.APIKey)
+
+	// Archiving a query removes the widgets of its visualizations
+	assert.Nil(c.ArchiveQuery(query.ID))
+	dashboard, _ = c.GetDashboard("service-slos")
+	assert.Len(dashboard.Widgets, 1)
+	assert.True(dashboard.Widgets[0].IsText())
+
+	queries, err := c.GetQueries()
+	assert.Nil(err)
+	assert.Equal(0, queries.Count)
+
+	assert.Nil(c.ArchiveDashboard("service-slos"))
+	dashboards, err := c.GetDashboards(1, 25)
+	assert.Nil(err)
+	assert.Equal(1, dashboards.Count)
+	assert.Equal("service-slos_2", dashboards.Results[0].Slug)
+}
+
+func TestAuthentication(t *testing.T) {
+	assert := assert.New(t)
+	server := NewServer()
+	defer server.Close()
+
+	c, _ := redash.NewClient(&redash.Config{RedashURI: server.URL, APIKey: "wrong"})
+	_, err := c.GetQueries()
+	assert.Contains(err.Error(), "Couldn't find resource. Please login and try again.")
+
+	admin := server.Client()
+	dataSource, err := admin.CreateDataSource(&redash.DataSource{Name: "Warehouse", Type: "pg", Options: map[string]interface{}{"dbname": "dwh"}})
+	assert.Nil(err)
+	revenue, err := admin.CreateQuery(&redash.QueryCreatePayload{Name: "Revenue", DataSourceID: dataSource.ID, Query: "SELECT 1"})
+	assert.Nil(err)
+	costs, err := admin.CreateQuery(&redash.QueryCreatePayload{Name: "Costs", DataSourceID: dataSource.ID, Query: "SELECT 2"})
+	assert.Nil(err)
+
+	_, err = admin.GetQueryResults(revenue.ID)
+	assert.Contains(err.Error(), "No cached result found for this query.")
+	result, err := server.AddQueryResult(revenue.ID, redash.QueryResultData{Rows: []map[string]interface{}{{"total": 1.0}}})
+	assert.Nil(err)
+	_, err = server.AddQueryResult(costs.ID, redash.QueryResultData{})
+	assert.Nil(err)
+
+	// A query's own key only reads the results of that query
+	reader, err := redash.NewClient(&redash.Config{RedashURI: server.URL, APIKey: revenue.APIKey, AuthMode: redash.AuthQueryAPIKey})
+	assert.Nil(err)
+	results, err := reader.GetQueryResults(revenue.ID)
+	assert.Nil(err)
+	assert.Equal(result.ID, results.ID)
+	assert.Equal(1.0, results.Data.Rows[0]["total"])
+	_, err = reader.GetQueryResult(result.ID)
+	assert.Nil(err)
+
+	_, err = reader.GetQueryResults(costs.ID)
+	assert.Contains(err.Error(), "Couldn't find resource. Please login and try again.")
+	_, err = reader.GetQueryResult(result.ID + 1)
+	assert.Contains(err.Error(), "Couldn't find resource. Please login and try again.")
+
+	key, _ := redash.NewClient(&redash.Config{RedashURI: server.URL, APIKey: revenue.APIKey})
+	_, err = key.GetQuery(revenue.ID)
+	assert.Contains(err.Error(), "Couldn't find resource. Please login and try again.")
+}
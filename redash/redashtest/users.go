@@ -0,0 +1,212 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redashtest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AlmirKadric/redash-client-go/redash"
+)
+
+func (s *Server) user(arg string) (*redash.User, error) {
+	user, ok := s.users[id(arg)]
+	if !ok {
+		return nil, notFound("User", arg)
+	}
+	return user, nil
+}
+
+// userListItem returns a user as listed, with its groups expanded
+func (s *Server) userListItem(user *redash.User) redash.UserListItem {
+	copy := *user
+	copy.Groups = nil
+	item := redash.UserListItem{}
+	convert(copy, &item)
+	item.Groups = []redash.UserListGroup{}
+	for _, id := range user.Groups {
+		if group, ok := s.groups[id]; ok {
+			item.Groups = append(item.Groups, redash.UserListGroup{ID: group.ID, Name: group.Name})
+		}
+	}
+	return item
+}
+
+// getUsers lists active users, or disabled ones with disabled=true, like Redash
+func (s *Server) getUsers(r *http.Request, args []string) (interface{}, error) {
+	parameters := r.URL.Query()
+	search := strings.ToLower(parameters.Get("q"))
+	disabled := parameters.Get("disabled") == "true"
+	pending := parameters.Get("pending")
+
+	users := []redash.UserListItem{}
+	for _, id := range sortedIDs(s.users) {
+		user := s.users[id]
+		if user.IsDisabled != disabled {
+			continue
+		}
+		if pending != "" && user.IsInvitationPending != (pending == "true") {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(user.Name), search) && !strings.Contains(strings.ToLower(user.Email), search) {
+			continue
+		}
+		users = append(users, s.userListItem(user))
+	}
+
+	number, size, start, end := page(r, len(users))
+	return list{Count: len(users), Page: number, PageSize: size, Results: users[start:end]}, nil
+}
+
+func (s *Server) getUser(r *http.Request, args []string) (interface{}, error) {
+	return s.user(args[0])
+}
+
+func (s *Server) createUser(r *http.Request, args []string) (interface{}, error) {
+	payload := redash.UserCreatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if err := s.validateUser(0, payload.Name, payload.Email); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	user := &redash.User{
+		ID:                  s.nextID("user"),
+		Name:                payload.Name,
+		Email:               payload.Email,
+		AuthType:            "password",
+		Groups:              []int{DefaultGroupID},
+		IsInvitationPending: true,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	user.APIKey = s.newKey()
+	user.InviteLink = s.URL + "/invite/" + user.APIKey
+	s.users[user.ID] = user
+
+	response := *user
+	user.InviteLink = ""
+	return response, nil
+}
+
+func (s *Server) validateUser(id int, name, email string) error {
+	if name == "" || email == "" {
+		return errorf(http.StatusBadRequest, "name and email are required")
+	}
+	if !strings.Contains(email, "@") {
+		return errorf(http.StatusBadRequest, "Invalid email.")
+	}
+	for _, other := range s.users {
+		if other.ID != id && strings.EqualFold(other.Email, email) {
+			return errorf(http.StatusBadRequest, "Email already taken.")
+		}
+	}
+	return nil
+}
+
+func (s *Server) updateUser(r *http.Request, args []string) (interface{}, error) {
+	user, err := s.user(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	payload := redash.UserUpdatePayload{}
+	if err := decode(r, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Name == "" {
+		payload.Name = user.Name
+	}
+	if payload.Email == "" {
+		payload.Email = user.Email
+	}
+	if err := s.validateUser(user.ID, payload.Name, payload.Email); err != nil {
+		return nil, err
+	}
+	for _, group := range payload.Groups {
+		if _, ok := s.groups[group]; !ok {
+			return nil, errorf(http.StatusBadRequest, "Group %d not found", group)
+		}
+	}
+
+	user.Name, user.Email = payload.Name, payload.Email
+	if payload.Groups != nil {
+		user.Groups = payload.Groups
+	}
+	user.UpdatedAt = time.Now().UTC()
+	return user, nil
+}
+
+func (s *Server) disableUser(r *http.Request, args []string) (interface{}, error) {
+	user, err := s.user(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == AdminUserID {
+		return nil, errorf(http.StatusBadRequest, "You cannot disable your own account. Please ask another admin to do this for you.")
+	}
+
+	user.IsDisabled = true
+	user.DisabledAt = time.Now().UTC()
+	return user, nil
+}
+
+func (s *Server) enableUser(r *http.Request, args []string) (interface{}, error) {
+	user, err := s.user(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	user.IsDisabled = false
+	user.DisabledAt = nil
+	return user, nil
+}
+
+func (s *Server) inviteUser(r *http.Request, args []string) (interface{}, error) {
+	user, err := s.user(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	response := *user
+	response.InviteLink = s.URL + "/invite/" + s.newKey()
+	return response, nil
+}
+
+func (s *Server) resetPassword(r *http.Request, args []string) (interface{}, error) {
+	user, err := s.user(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDisabled {
+		return nil, errorf(http.StatusNotFound, "Not found")
+	}
+	return redash.UserPasswordReset{ResetLink: s.URL + "/reset/" + s.newKey()}, nil
+}
+
+func (s *Server) regenerateAPIKey(r *http.Request, args []string) (interface{}, error) {
+	user, err := s.user(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	user.APIKey = s.newKey()
+	if user.ID == AdminUserID {
+		s.APIKey = user.APIKey
+	}
+	return user, nil
+}
@@ -0,0 +1,90 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+)
+
+// Session struct from Redash's /api/session endpoint
+type Session struct {
+	User         SessionUser            `json:"user"`
+	OrgSlug      string                 `json:"org_slug,omitempty"`
+	Messages     []string               `json:"messages,omitempty"`
+	ClientConfig map[string]interface{} `json:"client_config,omitempty"`
+}
+
+// SessionUser is the User bound to the API key along with its permissions
+type SessionUser struct {
+	User
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// HasPermission returns true if the session user has the given permission
+func (s *Session) HasPermission(permission string) bool {
+	for _, p := range s.User.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin returns true if the session user has admin rights on the organization
+func (s *Session) IsAdmin() bool {
+	return s.HasPermission("admin")
+}
+
+// GetSession returns the user, organization and permissions the configured APIKey acts as
+func (c *Client) GetSession() (*Session, error) {
+	path := "/api/session"
+
+	query := url.Values{}
+	response, err := c.get(path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	session := Session{}
+
+	err = json.Unmarshal(body, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// RequireAdmin returns an error unless the configured APIKey belongs to an organization admin
+func (c *Client) RequireAdmin() error {
+	session, err := c.GetSession()
+	if err != nil {
+		return err
+	}
+
+	if !session.IsAdmin() {
+		return fmt.Errorf("User %s is not an admin of organization %s", session.User.Email, session.OrgSlug)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSession(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/session",
+		httpmock.NewStringResponder(200, `{"user": {"id": 1, "name": "Admin", "email": "admin@example.com", "groups": [1, 2], "permissions": ["admin", "super_admin", "list_users"]}, "org_slug": "default", "messages": []}`))
+
+	session, err := c.GetSession()
+	assert.Nil(err)
+
+	assert.Equal(1, session.User.ID)
+	assert.Equal("admin@example.com", session.User.Email)
+	assert.Equal([]int{1, 2}, session.User.Groups)
+	assert.Equal("default", session.OrgSlug)
+	assert.Equal(true, session.IsAdmin())
+	assert.Equal(true, session.HasPermission("list_users"))
+	assert.Equal(false, session.HasPermission("edit_query"))
+}
+
+func TestRequireAdmin(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/session",
+		httpmock.NewStringResponder(200, `{"user": {"id": 2, "email": "viewer@example.com", "permissions": ["view_query"]}, "org_slug": "default"}`))
+
+	err := c.RequireAdmin()
+	assert.NotNil(err)
+}
@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+)
+
+// OrganizationSettings struct from Redash's /api/settings/organization endpoint
+type OrganizationSettings struct {
+	// Formats
+	DateFormat             string `json:"date_format,omitempty"`
+	TimeFormat             string `json:"time_format,omitempty"`
+	IntegerFormat          string `json:"integer_format,omitempty"`
+	FloatFormat            string `json:"float_format,omitempty"`
+	MultiByteSearchEnabled bool   `json:"multi_byte_search_enabled"`
+
+	// Features
+	FeatureShowPermissionsControl     bool  `json:"feature_show_permissions_control"`
+	SendEmailOnFailedScheduledQueries bool  `json:"send_email_on_failed_scheduled_queries"`
+	HidePlotlyModeBar                 bool  `json:"hide_plotly_mode_bar"`
+	DisablePublicURLs                 bool  `json:"disable_public_urls"`
+	BeaconConsent                     *bool `json:"beacon_consent"`
+
+	// Password & Google Auth
+	AuthPasswordLoginEnabled bool     `json:"auth_password_login_enabled"`
+	AuthGoogleAppsDomains    []string `json:"auth_google_apps_domains"`
+
+	// SAML Auth
+	AuthSAMLEnabled      bool   `json:"auth_saml_enabled"`
+	AuthSAMLType         string `json:"auth_saml_type,omitempty"`
+	AuthSAMLEntityID     string `json:"auth_saml_entity_id,omitempty"`
+	AuthSAMLMetadataURL  string `json:"auth_saml_metadata_url,omitempty"`
+	AuthSAMLNameIDFormat string `json:"auth_saml_nameid_format,omitempty"`
+	AuthSAMLSSOURL       string `json:"auth_saml_sso_url,omitempty"`
+	AuthSAMLX509Cert     string `json:"auth_saml_x509_cert,omitempty"`
+
+	// JWT Auth
+	AuthJWTLoginEnabled       bool     `json:"auth_jwt_login_enabled"`
+	AuthJWTAuthIssuer         string   `json:"auth_jwt_auth_issuer,omitempty"`
+	AuthJWTAuthPublicCertsURL string   `json:"auth_jwt_auth_public_certs_url,omitempty"`
+	AuthJWTAuthAudience       string   `json:"auth_jwt_auth_audience,omitempty"`
+	AuthJWTAuthAlgorithms     []string `json:"auth_jwt_auth_algorithms,omitempty"`
+	AuthJWTAuthCookieName     string   `json:"auth_jwt_auth_cookie_name,omitempty"`
+	AuthJWTAuthHeaderName     string   `json:"auth_jwt_auth_header_name,omitempty"`
+}
+
+// OrganizationSettingsUpdatePayload defines the schema for updating organization settings.
+// Only non-nil fields are sent, Redash leaves every other setting untouched.
+type OrganizationSettingsUpdatePayload struct {
+	// Formats
+	DateFormat             *string `json:"date_format,omitempty"`
+	TimeFormat             *string `json:"time_format,omitempty"`
+	IntegerFormat          *string `json:"integer_format,omitempty"`
+	FloatFormat            *string `json:"float_format,omitempty"`
+	MultiByteSearchEnabled *bool   `json:"multi_byte_search_enabled,omitempty"`
+
+	// Features
+	FeatureShowPermissionsControl     *bool `json:"feature_show_permissions_control,omitempty"`
+	SendEmailOnFailedScheduledQueries *bool `json:"send_email_on_failed_scheduled_queries,omitempty"`
+	HidePlotlyModeBar                 *bool `json:"hide_plotly_mode_bar,omitempty"`
+	DisablePublicURLs                 *bool `json:"disable_public_urls,omitempty"`
+	BeaconConsent                     *bool `json:"beacon_consent,omitempty"`
+
+	// Password & Google Auth
+	AuthPasswordLoginEnabled *bool    `json:"auth_password_login_enabled,omitempty"`
+	AuthGoogleAppsDomains    []string `json:"auth_google_apps_domains,omitempty"`
+
+	// SAML Auth
+	AuthSAMLEnabled      *bool   `json:"auth_saml_enabled,omitempty"`
+	AuthSAMLType         *string `json:"auth_saml_type,omitempty"`
+	AuthSAMLEntityID     *string `json:"auth_saml_entity_id,omitempty"`
+	AuthSAMLMetadataURL  *string `json:"auth_saml_metadata_url,omitempty"`
+	AuthSAMLNameIDFormat *string `json:"auth_saml_nameid_format,omitempty"`
+	AuthSAMLSSOURL       *string `json:"auth_saml_sso_url,omitempty"`
+	AuthSAMLX509Cert     *string `json:"auth_saml_x509_cert,omitempty"`
+
+	// JWT Auth
+	AuthJWTLoginEnabled       *bool    `json:"auth_jwt_login_enabled,omitempty"`
+	AuthJWTAuthIssuer         *string  `json:"auth_jwt_auth_issuer,omitempty"`
+	AuthJWTAuthPublicCertsURL *string  `json:"auth_jwt_auth_public_certs_url,omitempty"`
+	AuthJWTAuthAudience       *string  `json:"auth_jwt_auth_audience,omitempty"`
+	AuthJWTAuthAlgorithms     []string `json:"auth_jwt_auth_algorithms,omitempty"`
+	AuthJWTAuthCookieName     *string  `json:"auth_jwt_auth_cookie_name,omitempty"`
+	AuthJWTAuthHeaderName     *string  `json:"auth_jwt_auth_header_name,omitempty"`
+}
+
+type organizationSettingsResponse struct {
+	Settings OrganizationSettings `json:"settings"`
+}
+
+// GetOrganizationSettings returns the settings of the current organization
+func (c *Client) GetOrganizationSettings() (*OrganizationSettings, error) {
+	path := "/api/settings/organization"
+
+	query := url.Values{}
+	response, err := c.get(path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := organizationSettingsResponse{}
+
+	err = json.Unmarshal(body, &settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings.Settings, nil
+}
+
+// UpdateOrganizationSettings updates the settings of the current organization (admin only)
+func (c *Client) UpdateOrganizationSettings(settingsPayload *OrganizationSettingsUpdatePayload) (*OrganizationSettings, error) {
+	path := "/api/settings/organization"
+
+	payload, err := json.Marshal(settingsPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	response, err := c.post(path, string(payload), query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := organizationSettingsResponse{}
+
+	err = json.Unmarshal(body, &settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings.Settings, nil
+}
@@ -0,0 +1,65 @@
+//
+// Copyright (c) 2020-2022 Snowplow Analytics Ltd. All rights reserved.
+//
+// This program is licensed to you under the Apache License Version 2.0,
+// and you may not use this file except in compliance with the Apache License Version 2.0.
+// You may obtain a copy of the Apache License Version 2.0 at http://www.apache.org/licenses/LICENSE-2.0.
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the Apache License Version 2.0 is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the Apache License Version 2.0 for the specific language governing permissions and limitations there under.
+//
+
+package redash
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrganizationSettings(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/settings/organization",
+		httpmock.NewStringResponder(200, `{"settings": {"date_format": "DD/MM/YY", "auth_password_login_enabled": true, "auth_google_apps_domains": ["example.com"], "disable_public_urls": false}}`))
+
+	settings, err := c.GetOrganizationSettings()
+	assert.Nil(err)
+
+	assert.Equal("DD/MM/YY", settings.DateFormat)
+	assert.Equal(true, settings.AuthPasswordLoginEnabled)
+	assert.Equal([]string{"example.com"}, settings.AuthGoogleAppsDomains)
+	assert.Equal(false, settings.DisablePublicURLs)
+}
+
+func TestUpdateOrganizationSettings(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	var requestBody string
+	httpmock.RegisterResponder("POST", "https://com.acme/api/settings/organization",
+		func(request *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(request.Body)
+			requestBody = string(body)
+			return httpmock.NewStringResponse(200, `{"settings": {"date_format": "DD/MM/YY", "disable_public_urls": true}}`), nil
+		})
+
+	disable := true
+	settings, err := c.UpdateOrganizationSettings(&OrganizationSettingsUpdatePayload{DisablePublicURLs: &disable})
+	assert.Nil(err)
+
+	assert.Equal(`{"disable_public_urls":true}`, requestBody)
+	assert.Equal(true, settings.DisablePublicURLs)
+}
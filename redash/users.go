@@ -24,29 +24,51 @@ import (
 
 // UserList struct
 type UserList struct {
-	Count    int `json:"count"`
-	Page     int `json:"page"`
-	PageSize int `json:"page_size"`
-	Results  []struct {
-		AuthType            string    `json:"auth_type,omitempty"`
-		IsDisabled          bool      `json:"is_disabled,omitempty"`
-		UpdatedAt           time.Time `json:"updated_at,omitempty"`
-		ProfileImageURL     string    `json:"profile_image_url,omitempty"`
-		IsInvitationPending bool      `json:"is_invitation_pending,omitempty"`
-		Groups              []struct {
-			ID   int    `json:"id,omitempty"`
-			Name string `json:"name,omitempty"`
-		} `json:"groups,omitempty"`
-		ID              int         `json:"id,omitempty"`
-		Name            string      `json:"name,omitempty"`
-		CreatedAt       time.Time   `json:"created_at,omitempty"`
-		DisabledAt      interface{} `json:"disabled_at,omitempty"`
-		IsEmailVerified bool        `json:"is_email_verified,omitempty"`
-		ActiveAt        time.Time   `json:"active_at,omitempty"`
-		Email           string      `json:"email,omitempty"`
-	} `json:"results,omitempty"`
+	Count    int            `json:"count"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	Results  []UserListItem `json:"results,omitempty"`
 }
 
+// UserListItem struct for UserList results, groups are expanded to their id and name
+type UserListItem struct {
+	AuthType            string          `json:"auth_type,omitempty"`
+	IsDisabled          bool            `json:"is_disabled,omitempty"`
+	UpdatedAt           time.Time       `json:"updated_at,omitempty"`
+	ProfileImageURL     string          `json:"profile_image_url,omitempty"`
+	IsInvitationPending bool            `json:"is_invitation_pending,omitempty"`
+	Groups              []UserListGroup `json:"groups,omitempty"`
+	ID                  int             `json:"id,omitempty"`
+	Name                string          `json:"name,omitempty"`
+	CreatedAt           time.Time       `json:"created_at,omitempty"`
+	DisabledAt          interface{}     `json:"disabled_at,omitempty"`
+	IsEmailVerified     bool            `json:"is_email_verified,omitempty"`
+	ActiveAt            time.Time       `json:"active_at,omitempty"`
+	Email               string          `json:"email,omitempty"`
+}
+
+// UserListGroup struct for the groups of a UserListItem
+type UserListGroup struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// UserListOptions struct for filtering user lists. Nil and zero values are not sent, Redash then
+// lists active users of any invitation state.
+// Redash cannot filter users by group, GroupIDs and GroupNames are applied client side by
+// EachUser, a user matches if it belongs to any of them. GetUsers and SearchUsers reject them,
+// as the count of a page filtered afterwards would not match its results.
+type UserListOptions struct {
+	Search     string
+	Disabled   *bool
+	Pending    *bool
+	GroupIDs   []int
+	GroupNames []string
+}
+
+// userPageSize is the page size EachUser walks users with
+const userPageSize = 100
+
 // User representation
 type User struct {
 	AuthType            string      `json:"auth_type,omitempty"`
@@ -62,6 +84,13 @@ type User struct {
 	IsEmailVerified     bool        `json:"is_email_verified,omitempty"`
 	ActiveAt            time.Time   `json:"active_at,omitempty"`
 	Email               string      `json:"email,omitempty"`
+	APIKey              string      `json:"api_key,omitempty"`
+	InviteLink          string      `json:"invite_link,omitempty"`
+}
+
+// UserPasswordReset struct, ResetLink is only populated when Redash has no mail server configured
+type UserPasswordReset struct {
+	ResetLink string `json:"reset_link,omitempty"`
 }
 
 // UserCreatePayload struct for mutating users.
@@ -77,19 +106,91 @@ type UserUpdatePayload struct {
 	Groups []int  `json:"group_ids"`
 }
 
-// GetUsers returns a paginated list of users
-func (c *Client) GetUsers(page, pageSize int) (*UserList, error) {
+// ToUser converts a UserListItem into a User, flattening groups to their ids
+func (u *UserListItem) ToUser() *User {
+	groups := make([]int, 0, len(u.Groups))
+	for _, group := range u.Groups {
+		groups = append(groups, group.ID)
+	}
+
+	return &User{
+		AuthType:            u.AuthType,
+		IsDisabled:          u.IsDisabled,
+		UpdatedAt:           u.UpdatedAt,
+		ProfileImageURL:     u.ProfileImageURL,
+		IsInvitationPending: u.IsInvitationPending,
+		Groups:              groups,
+		ID:                  u.ID,
+		Name:                u.Name,
+		CreatedAt:           u.CreatedAt,
+		DisabledAt:          u.DisabledAt,
+		IsEmailVerified:     u.IsEmailVerified,
+		ActiveAt:            u.ActiveAt,
+		Email:               u.Email,
+	}
+}
+
+// InGroup returns true if the user belongs to any of the given group ids or names
+func (u *UserListItem) InGroup(ids []int, names []string) bool {
+	for _, group := range u.Groups {
+		for _, id := range ids {
+			if group.ID == id {
+				return true
+			}
+		}
+		for _, name := range names {
+			if group.Name == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// GetUsers returns a paginated list of users, filtered by options when given
+func (c *Client) GetUsers(page, pageSize int, options ...*UserListOptions) (*UserList, error) {
+	filters := UserListOptions{}
+	if len(options) > 0 && options[0] != nil {
+		filters = *options[0]
+	}
+	return c.listUsers(&filters, page, pageSize)
+}
+
+// listUsers requests a page of users, page and pageSize are not sent when 0
+func (c *Client) listUsers(options *UserListOptions, page, pageSize int) (*UserList, error) {
 	path := "/api/users"
 
+	if len(options.GroupIDs) > 0 || len(options.GroupNames) > 0 {
+		return nil, fmt.Errorf("users can only be filtered by group with EachUser")
+	}
+
 	query := url.Values{}
-	query.Add("page", strconv.Itoa(page))
-	query.Add("page_size", strconv.Itoa(pageSize))
+	if options.Search != "" {
+		query.Add("q", options.Search)
+	}
+	if options.Disabled != nil {
+		query.Add("disabled", strconv.FormatBool(*options.Disabled))
+	}
+	if options.Pending != nil {
+		query.Add("pending", strconv.FormatBool(*options.Pending))
+	}
+	if page > 0 {
+		query.Add("page", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		query.Add("page_size", strconv.Itoa(pageSize))
+	}
 	response, err := c.get(path, query)
 
 	if err != nil {
 		return nil, err
 	}
-	body, _ := ioutil.ReadAll(response.Body)
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
 
 	users := UserList{}
 	err = json.Unmarshal(body, &users)
@@ -97,11 +198,37 @@ func (c *Client) GetUsers(page, pageSize int) (*UserList, error) {
 		return nil, err
 	}
 
-	defer response.Body.Close()
-
 	return &users, nil
 }
 
+// EachUser walks every page of users matching the given options and calls fn for each one.
+// Iteration stops at the first error returned by fn.
+func (c *Client) EachUser(options *UserListOptions, fn func(user *UserListItem) error) error {
+	pageOptions := *options
+	pageOptions.GroupIDs, pageOptions.GroupNames = nil, nil
+	filterGroups := len(options.GroupIDs) > 0 || len(options.GroupNames) > 0
+
+	for page := 1; ; page++ {
+		users, err := c.listUsers(&pageOptions, page, userPageSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range users.Results {
+			if filterGroups && !users.Results[i].InGroup(options.GroupIDs, options.GroupNames) {
+				continue
+			}
+			if err := fn(&users.Results[i]); err != nil {
+				return err
+			}
+		}
+
+		if users.Count == 0 || page*userPageSize >= users.Count {
+			return nil
+		}
+	}
+}
+
 // GetUser gets a specific User
 func (c *Client) GetUser(id int) (*User, error) {
 	path := "/api/users/" + strconv.Itoa(id)
@@ -209,28 +336,123 @@ func (c *Client) DisableUser(id int) error {
 	return nil
 }
 
-// SearchUsers finds a list of users matching a string (searches `name` and `email` fields)
-func (c *Client) SearchUsers(term string) (*UserList, error) {
-	path := "/api/users"
+// EnableUser re-enables a disabled user.
+func (c *Client) EnableUser(id int) (*User, error) {
+	path := "/api/users/" + strconv.Itoa(id) + "/disable"
 
 	query := url.Values{}
-	query.Add("q", term)
-	response, err := c.get(path, query)
+	response, err := c.delete(path, query)
+	if err != nil {
+		return nil, err
+	}
 
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
 		return nil, err
 	}
-	body, _ := ioutil.ReadAll(response.Body)
 
-	users := UserList{}
-	err = json.Unmarshal(body, &users)
+	user := User{}
+
+	err = json.Unmarshal(body, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ResendInvitation sends a new invitation to a user whose invitation is still pending.
+// The returned User carries the InviteLink when Redash has no mail server configured.
+func (c *Client) ResendInvitation(id int) (*User, error) {
+	path := "/api/users/" + strconv.Itoa(id) + "/invite"
+
+	query := url.Values{}
+	response, err := c.post(path, "", query)
 	if err != nil {
 		return nil, err
 	}
 
 	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
 
-	return &users, nil
+	user := User{}
+
+	err = json.Unmarshal(body, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ResetUserPassword triggers a password reset for a user.
+func (c *Client) ResetUserPassword(id int) (*UserPasswordReset, error) {
+	path := "/api/users/" + strconv.Itoa(id) + "/reset_password"
+
+	query := url.Values{}
+	response, err := c.post(path, "", query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	reset := UserPasswordReset{}
+	if len(body) == 0 {
+		return &reset, nil
+	}
+
+	err = json.Unmarshal(body, &reset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reset, nil
+}
+
+// RegenerateUserAPIKey issues a new API key for a user, the previous key stops working immediately.
+func (c *Client) RegenerateUserAPIKey(id int) (*User, error) {
+	path := "/api/users/" + strconv.Itoa(id) + "/regenerate_api_key"
+
+	query := url.Values{}
+	response, err := c.post(path, "", query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	user := User{}
+
+	err = json.Unmarshal(body, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// SearchUsers finds a list of users matching a string (searches `name` and `email` fields),
+// filtered by options when given
+func (c *Client) SearchUsers(term string, options ...*UserListOptions) (*UserList, error) {
+	filters := UserListOptions{}
+	if len(options) > 0 && options[0] != nil {
+		filters = *options[0]
+	}
+	filters.Search = term
+	return c.listUsers(&filters, 0, 0)
 }
 
 // GetUserByEmail returns a single  user from their email address
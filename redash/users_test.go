@@ -123,3 +123,131 @@ func TestDisableUser(t *testing.T) {
 
 	assert.Nil(err)
 }
+
+func TestEnableUser(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/users/1/disable",
+		httpmock.NewStringResponder(200, `{"id": 1, "name": "Existing User", "is_disabled": false}`))
+
+	user, err := c.EnableUser(1)
+	assert.Nil(err)
+
+	assert.Equal(1, user.ID)
+	assert.Equal(false, user.IsDisabled)
+}
+
+func TestResendInvitation(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/users/1/invite",
+		httpmock.NewStringResponder(200, `{"id": 1, "is_invitation_pending": true, "invite_link": "https://com.acme/invite/ToKeN"}`))
+
+	user, err := c.ResendInvitation(1)
+	assert.Nil(err)
+
+	assert.Equal(1, user.ID)
+	assert.Equal(true, user.IsInvitationPending)
+	assert.Equal("https://com.acme/invite/ToKeN", user.InviteLink)
+}
+
+func TestResetUserPassword(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/users/1/reset_password",
+		httpmock.NewStringResponder(200, `{"reset_link": "https://com.acme/reset/ToKeN"}`))
+
+	reset, err := c.ResetUserPassword(1)
+	assert.Nil(err)
+	assert.Equal("https://com.acme/reset/ToKeN", reset.ResetLink)
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/users/2/reset_password",
+		httpmock.NewStringResponder(200, ""))
+
+	reset, err = c.ResetUserPassword(2)
+	assert.Nil(err)
+	assert.Equal("", reset.ResetLink)
+}
+
+func TestRegenerateUserAPIKey(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/users/1/regenerate_api_key",
+		httpmock.NewStringResponder(200, `{"id": 1, "api_key": "NeWkEy"}`))
+
+	user, err := c.RegenerateUserAPIKey(1)
+	assert.Nil(err)
+
+	assert.Equal(1, user.ID)
+	assert.Equal("NeWkEy", user.APIKey)
+}
+
+func TestGetUsersWithOptions(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/users?disabled=true&page=1&page_size=2",
+		httpmock.NewStringResponder(200, `{"count": 3, "page": 1, "page_size": 2, "results": [
+			{"id": 1, "is_disabled": true, "groups": [{"id": 1, "name": "admin"}, {"id": 2, "name": "default"}]},
+			{"id": 2, "is_disabled": true, "groups": [{"id": 2, "name": "default"}]}
+		]}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/users?disabled=true&q=ada",
+		httpmock.NewStringResponder(200, `{"count": 1, "page": 1, "page_size": 20, "results": [{"id": 1, "is_disabled": true}]}`))
+
+	// EachUser pages by 100, the counts pretend there are more users than returned
+	httpmock.RegisterResponder("GET", "https://com.acme/api/users?disabled=true&page=1&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 101, "page": 1, "page_size": 100, "results": [
+			{"id": 1, "is_disabled": true, "groups": [{"id": 1, "name": "admin"}, {"id": 2, "name": "default"}]},
+			{"id": 2, "is_disabled": true, "groups": [{"id": 2, "name": "default"}]}
+		]}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/users?disabled=true&page=2&page_size=100",
+		httpmock.NewStringResponder(200, `{"count": 101, "page": 2, "page_size": 100, "results": [
+			{"id": 3, "is_disabled": true, "groups": [{"id": 1, "name": "admin"}]}
+		]}`))
+
+	disabled := true
+	users, err := c.GetUsers(1, 2, &UserListOptions{Disabled: &disabled})
+	assert.Nil(err)
+	assert.Equal(3, users.Count)
+	assert.Equal(2, len(users.Results))
+	assert.Equal(1, users.Results[0].ID)
+
+	// Group filters only make sense across every page
+	_, err = c.GetUsers(1, 2, &UserListOptions{Disabled: &disabled, GroupNames: []string{"admin"}})
+	assert.EqualError(err, "users can only be filtered by group with EachUser")
+
+	found, err := c.SearchUsers("ada", &UserListOptions{Disabled: &disabled})
+	assert.Nil(err)
+	assert.Equal(1, found.Results[0].ID)
+
+	user := users.Results[0].ToUser()
+	assert.Equal(1, user.ID)
+	assert.Equal([]int{1, 2}, user.Groups)
+
+	ids := []int{}
+	err = c.EachUser(&UserListOptions{Disabled: &disabled, GroupIDs: []int{1}}, func(user *UserListItem) error {
+		ids = append(ids, user.ID)
+		return nil
+	})
+	assert.Nil(err)
+	assert.Equal([]int{1, 3}, ids)
+}
@@ -0,0 +1,534 @@
+package redash
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Column roles used in ChartOptions.ColumnMapping
+const (
+	ChartColumnX      = "x"
+	ChartColumnY      = "y"
+	ChartColumnSeries = "series"
+	ChartColumnSize   = "size"
+	ChartColumnYError = "yError"
+)
+
+// ChartBuilder builds the payload of a CHART visualization with Redash's default options
+type ChartBuilder struct {
+	name        string
+	description string
+	queryID     int
+	options     ChartOptions
+	err         error
+}
+
+// SeriesOption customises a Y series added with ChartBuilder.Y
+type SeriesOption func(option *ChartSeriesOption)
+
+// NewChart returns a ChartBuilder for the given global series type
+// (line, column, area, pie, scatter, bubble, heatmap or box)
+func NewChart(seriesType string) *ChartBuilder {
+	builder := &ChartBuilder{
+		name: "Chart",
+		options: ChartOptions{
+			GlobalSeriesType:    seriesType,
+			ColumnMapping:       ChartColumnsMapping{},
+			ErrorY:              ChartErrorY{Type: "data", Visible: true},
+			Legend:              ChartLegend{Enabled: true, Extra: map[string]interface{}{"placement": "auto"}},
+			Series:              ChartSeries{ErrorY: ChartErrorY{Type: "data", Visible: true}},
+			MissingValuesAsZero: true,
+			XAxis:               ChartXAxis{Type: "-"},
+			SortX:               true,
+			YAxis:               []ChartYAxis{{Type: "linear"}, {Type: "linear", Opposite: true}},
+			SeriesOptions:       ChartSeriesOptions{},
+			ShowDataLabels:      seriesType == "pie",
+			NumberFormat:        "0,0[.]00000",
+			PercentFormat:       "0[.]00%",
+			DateTimeFormat:      "DD/MM/YYYY HH:mm",
+			Extra: map[string]interface{}{
+				"alignYAxesAtZero": false,
+				"coefficient":      1,
+				"direction":        map[string]interface{}{"type": "counterclockwise"},
+				"sizemode":         "diameter",
+				"valuesOptions":    map[string]interface{}{},
+			},
+		},
+	}
+	builder.options.XAxis.Labels.Enabled = true
+
+	return builder
+}
+
+// NewLineChart returns a ChartBuilder for a line chart
+func NewLineChart() *ChartBuilder {
+	return NewChart("line")
+}
+
+// NewBarChart returns a ChartBuilder for a bar (column) chart
+func NewBarChart() *ChartBuilder {
+	return NewChart("column")
+}
+
+// NewAreaChart returns a ChartBuilder for an area chart
+func NewAreaChart() *ChartBuilder {
+	return NewChart("area")
+}
+
+// NewPieChart returns a ChartBuilder for a pie chart
+func NewPieChart() *ChartBuilder {
+	return NewChart("pie")
+}
+
+// NewScatterChart returns a ChartBuilder for a scatter chart
+func NewScatterChart() *ChartBuilder {
+	return NewChart("scatter")
+}
+
+// Name sets the visualization name
+func (b *ChartBuilder) Name(name string) *ChartBuilder {
+	b.name = name
+	return b
+}
+
+// Description sets the visualization description
+func (b *ChartBuilder) Description(description string) *ChartBuilder {
+	b.description = description
+	return b
+}
+
+// Query sets the query the visualization belongs to
+func (b *ChartBuilder) Query(queryID int) *ChartBuilder {
+	b.queryID = queryID
+	return b
+}
+
+// X maps a column to the X axis
+func (b *ChartBuilder) X(column string) *ChartBuilder {
+	b.mapColumn(column, ChartColumnX)
+	return b
+}
+
+// Y maps a column to a Y series
+func (b *ChartBuilder) Y(column string, options ...SeriesOption) *ChartBuilder {
+	b.mapColumn(column, ChartColumnY)
+
+	series := ChartSeriesOption{
+		ZIndex: len(b.options.SeriesOptions),
+		Index:  len(b.options.SeriesOptions),
+		Type:   b.options.GlobalSeriesType,
+		YAxis:  0,
+	}
+	for _, option := range options {
+		option(&series)
+	}
+	if series.YAxis < 0 || series.YAxis >= len(b.options.YAxis) {
+		b.fail(fmt.Errorf("series %s uses Y axis %d, charts only have %d", column, series.YAxis, len(b.options.YAxis)))
+	}
+	b.options.SeriesOptions[column] = series
+
+	return b
+}
+
+// GroupBy maps a column whose values split the data into separate series
+func (b *ChartBuilder) GroupBy(column string) *ChartBuilder {
+	b.mapColumn(column, ChartColumnSeries)
+	return b
+}
+
+// Size maps a column to the bubble size of bubble charts
+func (b *ChartBuilder) Size(column string) *ChartBuilder {
+	b.mapColumn(column, ChartColumnSize)
+	return b
+}
+
+// ErrorColumn maps a column to the Y error bars
+func (b *ChartBuilder) ErrorColumn(column string) *ChartBuilder {
+	b.mapColumn(column, ChartColumnYError)
+	return b
+}
+
+// Stack sets series stacking, "normal" or "percent". An empty string disables stacking.
+func (b *ChartBuilder) Stack(stacking string) *ChartBuilder {
+	switch stacking {
+	case "":
+		b.options.Series.Stacking = nil
+	case "normal", "percent":
+		b.options.Series.Stacking = &stacking
+	default:
+		b.fail(fmt.Errorf("invalid stacking %q", stacking))
+	}
+	return b
+}
+
+// Legend shows or hides the legend
+func (b *ChartBuilder) Legend(enabled bool) *ChartBuilder {
+	b.options.Legend.Enabled = enabled
+	return b
+}
+
+// XAxisType sets the X axis scale: "-" (auto), "datetime", "linear", "logarithmic" or "category"
+func (b *ChartBuilder) XAxisType(axisType string) *ChartBuilder {
+	b.options.XAxis.Type = axisType
+	return b
+}
+
+// XTitle sets the X axis title
+func (b *ChartBuilder) XTitle(title string) *ChartBuilder {
+	b.options.XAxis.Title = &ChartAxisTitle{Text: title}
+	return b
+}
+
+// YAxisType sets the scale of a Y axis: "linear", "logarithmic", "datetime" or "category"
+func (b *ChartBuilder) YAxisType(axis int, axisType string) *ChartBuilder {
+	if b.checkAxis(axis) {
+		b.options.YAxis[axis].Type = axisType
+	}
+	return b
+}
+
+// YTitle sets the title of a Y axis
+func (b *ChartBuilder) YTitle(axis int, title string) *ChartBuilder {
+	if b.checkAxis(axis) {
+		b.options.YAxis[axis].Title = &ChartAxisTitle{Text: title}
+	}
+	return b
+}
+
+// SortX sorts values on the X axis
+func (b *ChartBuilder) SortX(sort bool) *ChartBuilder {
+	b.options.SortX = sort
+	return b
+}
+
+// DataLabels shows or hides data labels
+func (b *ChartBuilder) DataLabels(show bool) *ChartBuilder {
+	b.options.ShowDataLabels = show
+	return b
+}
+
+// NumberFormat sets the numeral.js format of data labels
+func (b *ChartBuilder) NumberFormat(format string) *ChartBuilder {
+	b.options.NumberFormat = format
+	return b
+}
+
+// Options returns the ChartOptions built so far
+func (b *ChartBuilder) Options() (*ChartOptions, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	hasX, hasY := false, false
+	for _, role := range b.options.ColumnMapping {
+		hasX = hasX || role == ChartColumnX
+		hasY = hasY || role == ChartColumnY
+	}
+	if !hasX || !hasY {
+		return nil, fmt.Errorf("chart %q needs an X and at least one Y column", b.name)
+	}
+
+	options := new(ChartOptions)
+	if err := copyOptions(b.options, options); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// Build returns the payload to create the visualization. Its QueryId is only set when Query
+// was called, otherwise set it on the payload before creating the visualization.
+func (b *ChartBuilder) Build() (*VisualizationCreatePayload, error) {
+	options, err := b.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return &VisualizationCreatePayload{
+		Name:        b.name,
+		Description: b.description,
+		Type:        VisualizationTypeChart,
+		Options:     options,
+		QueryId:     b.queryID,
+	}, nil
+}
+
+// BuildUpdate returns the payload to update an existing visualization
+func (b *ChartBuilder) BuildUpdate() (*VisualizationUpdatePayload, error) {
+	options, err := b.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return &VisualizationUpdatePayload{
+		Name:        b.name,
+		Description: b.description,
+		Type:        VisualizationTypeChart,
+		Options:     options,
+	}, nil
+}
+
+func (b *ChartBuilder) mapColumn(column, role string) {
+	if existing, ok := b.options.ColumnMapping[column]; ok && existing != role {
+		b.fail(fmt.Errorf("column %s is already mapped to %s", column, existing))
+		return
+	}
+	b.options.ColumnMapping[column] = role
+}
+
+func (b *ChartBuilder) checkAxis(axis int) bool {
+	if axis < 0 || axis >= len(b.options.YAxis) {
+		b.fail(fmt.Errorf("invalid Y axis %d", axis))
+		return false
+	}
+	return true
+}
+
+func (b *ChartBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Axis plots the series against the given Y axis, 0 (left) or 1 (right)
+func Axis(axis int) SeriesOption {
+	return func(option *ChartSeriesOption) {
+		option.YAxis = axis
+	}
+}
+
+// SeriesType overrides the global series type for a single series
+func SeriesType(seriesType string) SeriesOption {
+	return func(option *ChartSeriesOption) {
+		option.Type = seriesType
+	}
+}
+
+// SeriesLabel sets the name displayed for the series
+func SeriesLabel(name string) SeriesOption {
+	return func(option *ChartSeriesOption) {
+		option.Name = name
+	}
+}
+
+// SeriesColor sets the series color, e.g. "#356AFF"
+func SeriesColor(color string) SeriesOption {
+	return func(option *ChartSeriesOption) {
+		option.Color = color
+	}
+}
+
+// copyOptions deep copies options through their JSON form, which Extra keeps complete, so
+// the payloads built are not changed by later calls on the builder
+func copyOptions(options, copied interface{}) error {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, copied)
+}
+
+// TableBuilder builds the payload of a TABLE visualization with Redash's default column options
+type TableBuilder struct {
+	name        string
+	description string
+	queryID     int
+	options     TableOptions
+	err         error
+}
+
+// ColumnOption customises a column added with TableBuilder.Column
+type ColumnOption func(column *TableColumn)
+
+// NewTable returns a TableBuilder
+func NewTable() *TableBuilder {
+	return &TableBuilder{
+		name:    "Table",
+		options: TableOptions{ItemsPerPage: 25, Columns: []TableColumn{}},
+	}
+}
+
+// Name sets the visualization name
+func (b *TableBuilder) Name(name string) *TableBuilder {
+	b.name = name
+	return b
+}
+
+// Description sets the visualization description
+func (b *TableBuilder) Description(description string) *TableBuilder {
+	b.description = description
+	return b
+}
+
+// Query sets the query the visualization belongs to
+func (b *TableBuilder) Query(queryID int) *TableBuilder {
+	b.queryID = queryID
+	return b
+}
+
+// ItemsPerPage sets the page size of the table
+func (b *TableBuilder) ItemsPerPage(items int) *TableBuilder {
+	b.options.ItemsPerPage = items
+	return b
+}
+
+// Column adds a column, columns are displayed in the order they are added
+func (b *TableBuilder) Column(name string, options ...ColumnOption) *TableBuilder {
+	for _, column := range b.options.Columns {
+		if column.Name == name {
+			b.fail(fmt.Errorf("column %s added twice", name))
+			return b
+		}
+	}
+
+	column := NewTableColumn(name)
+	column.Order = 100000 + len(b.options.Columns)
+	for _, option := range options {
+		option(&column)
+	}
+	b.options.Columns = append(b.options.Columns, column)
+
+	return b
+}
+
+// Options returns the TableOptions built so far
+func (b *TableBuilder) Options() (*TableOptions, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	options := new(TableOptions)
+	if err := copyOptions(b.options, options); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// Build returns the payload to create the visualization. Its QueryId is only set when Query
+// was called, otherwise set it on the payload before creating the visualization.
+func (b *TableBuilder) Build() (*VisualizationCreatePayload, error) {
+	options, err := b.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return &VisualizationCreatePayload{
+		Name:        b.name,
+		Description: b.description,
+		Type:        VisualizationTypeTable,
+		Options:     options,
+		QueryId:     b.queryID,
+	}, nil
+}
+
+// BuildUpdate returns the payload to update an existing visualization
+func (b *TableBuilder) BuildUpdate() (*VisualizationUpdatePayload, error) {
+	options, err := b.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return &VisualizationUpdatePayload{
+		Name:        b.name,
+		Description: b.description,
+		Type:        VisualizationTypeTable,
+		Options:     options,
+	}, nil
+}
+
+func (b *TableBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// NewTableColumn returns a TableColumn with Redash's defaults for a plain text column
+func NewTableColumn(name string) TableColumn {
+	return TableColumn{
+		Visible:            true,
+		Name:               name,
+		Title:              name,
+		Type:               "string",
+		DisplayAs:          "string",
+		AlignContent:       "left",
+		AllowHTML:          true,
+		BooleanValues:      []string{"false", "true"},
+		LinkUrlTemplate:    "{{ @ }}",
+		LinkTextTemplate:   "{{ @ }}",
+		LinkOpenInNewTab:   true,
+		ImageUrlTemplate:   "{{ @ }}",
+		ImageTitleTemplate: "{{ @ }}",
+	}
+}
+
+// ColumnTitle sets the column header
+func ColumnTitle(title string) ColumnOption {
+	return func(column *TableColumn) {
+		column.Title = title
+	}
+}
+
+// ColumnHidden hides the column
+func ColumnHidden() ColumnOption {
+	return func(column *TableColumn) {
+		column.Visible = false
+	}
+}
+
+// ColumnAlign aligns the column content: "left", "center" or "right"
+func ColumnAlign(align string) ColumnOption {
+	return func(column *TableColumn) {
+		column.AlignContent = align
+	}
+}
+
+// ColumnSearchable includes the column in the table search
+func ColumnSearchable() ColumnOption {
+	return func(column *TableColumn) {
+		column.AllowSearch = true
+	}
+}
+
+// ColumnNumber displays the column as a right aligned number using a numeral.js format
+func ColumnNumber(format string) ColumnOption {
+	return func(column *TableColumn) {
+		column.Type = "float"
+		column.DisplayAs = "number"
+		column.AlignContent = "right"
+		column.NumberFormat = format
+	}
+}
+
+// ColumnDateTime displays the column as a date using a moment.js format
+func ColumnDateTime(format string) ColumnOption {
+	return func(column *TableColumn) {
+		column.Type = "datetime"
+		column.DisplayAs = "datetime"
+		column.DateTimeFormat = format
+	}
+}
+
+// ColumnBoolean displays the column as a boolean with the given labels
+func ColumnBoolean(falseValue, trueValue string) ColumnOption {
+	return func(column *TableColumn) {
+		column.Type = "boolean"
+		column.DisplayAs = "boolean"
+		column.BooleanValues = []string{falseValue, trueValue}
+	}
+}
+
+// ColumnLink displays the column as a link, templates may reference columns as {{ column }} and the value as {{ @ }}
+func ColumnLink(urlTemplate, textTemplate string) ColumnOption {
+	return func(column *TableColumn) {
+		column.DisplayAs = "link"
+		column.LinkUrlTemplate = urlTemplate
+		column.LinkTextTemplate = textTemplate
+	}
+}
+
+// ColumnImage displays the column as an image
+func ColumnImage(urlTemplate string) ColumnOption {
+	return func(column *TableColumn) {
+		column.DisplayAs = "image"
+		column.ImageUrlTemplate = urlTemplate
+	}
+}
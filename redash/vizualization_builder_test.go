@@ -0,0 +1,138 @@
+package redash
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChartBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	payload, err := NewLineChart().
+		Name("Revenue").
+		Query(12).
+		X("day").
+		Y("revenue", Axis(0)).
+		Y("orders", Axis(1), SeriesType("column"), SeriesLabel("Orders")).
+		Stack("normal").
+		Legend(false).
+		YTitle(1, "Orders").
+		Build()
+	assert.Nil(err)
+
+	assert.Equal("Revenue", payload.Name)
+	assert.Equal(VisualizationTypeChart, payload.Type)
+	assert.Equal(12, payload.QueryId)
+
+	options := payload.Options.(*ChartOptions)
+	assert.Equal("line", options.GlobalSeriesType)
+	assert.Equal(ChartColumnsMapping{"day": "x", "revenue": "y", "orders": "y"}, options.ColumnMapping)
+	assert.Equal("normal", *options.Series.Stacking)
+	assert.Equal(false, options.Legend.Enabled)
+	assert.Equal(1, options.SeriesOptions["orders"].YAxis)
+	assert.Equal("column", options.SeriesOptions["orders"].Type)
+	assert.Equal("Orders", options.SeriesOptions["orders"].Name)
+	assert.Equal(1, options.SeriesOptions["orders"].Index)
+	assert.Equal("Orders", options.YAxis[1].Title.Text)
+
+	encoded, err := json.Marshal(payload)
+	assert.Nil(err)
+	decoded := map[string]interface{}{}
+	assert.Nil(json.Unmarshal(encoded, &decoded))
+	encodedOptions := decoded["options"].(map[string]interface{})
+	assert.Equal("diameter", encodedOptions["sizemode"])
+	assert.Equal("auto", encodedOptions["legend"].(map[string]interface{})["placement"])
+}
+
+func TestChartBuilderErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewBarChart().Query(1).X("day").Build()
+	assert.NotNil(err)
+
+	_, err = NewBarChart().Query(1).X("day").Y("day").Build()
+	assert.NotNil(err)
+
+	_, err = NewBarChart().Query(1).X("day").Y("value", Axis(2)).Build()
+	assert.NotNil(err)
+
+	_, err = NewBarChart().Query(1).X("day").Y("value").Stack("sideways").Build()
+	assert.NotNil(err)
+
+	update, err := NewBarChart().X("day").Y("value").BuildUpdate()
+	assert.Nil(err)
+	assert.Equal(VisualizationTypeChart, update.Type)
+}
+
+func TestChartBuilderWithoutQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	payload, err := NewLineChart().X("day").Y("revenue", Axis(0)).Stack("normal").Legend(true).Build()
+	assert.Nil(err)
+	assert.Equal(0, payload.QueryId)
+	assert.Equal(VisualizationTypeChart, payload.Type)
+	assert.Equal("normal", *payload.Options.(*ChartOptions).Series.Stacking)
+}
+
+func TestTableBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	payload, err := NewTable().
+		Name("Orders").
+		Query(12).
+		ItemsPerPage(50).
+		Column("day", ColumnTitle("Day"), ColumnDateTime("YYYY-MM-DD")).
+		Column("revenue", ColumnNumber("$0,0.00")).
+		Column("internal_id", ColumnHidden()).
+		Build()
+	assert.Nil(err)
+
+	assert.Equal(VisualizationTypeTable, payload.Type)
+	options := payload.Options.(*TableOptions)
+	assert.Equal(50, options.ItemsPerPage)
+	assert.Equal(3, len(options.Columns))
+
+	day := options.Columns[0]
+	assert.Equal("Day", day.Title)
+	assert.Equal("datetime", day.DisplayAs)
+	assert.Equal("YYYY-MM-DD", day.DateTimeFormat)
+	assert.Equal(true, day.Visible)
+
+	revenue := options.Columns[1]
+	assert.Equal("number", revenue.DisplayAs)
+	assert.Equal("right", revenue.AlignContent)
+	assert.Equal(day.Order+1, revenue.Order)
+
+	assert.Equal(false, options.Columns[2].Visible)
+
+	_, err = NewTable().Query(12).Column("day").Column("day").Build()
+	assert.NotNil(err)
+}
+
+func TestBuildersCopyOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	// Payloads already built do not change with the builder they came from
+	chart := NewLineChart().Query(12).X("day").Y("revenue").Stack("normal").YTitle(0, "Revenue")
+	payload, err := chart.Build()
+	assert.Nil(err)
+	chart.Y("orders").Stack("percent").YTitle(0, "Orders").XTitle("Day")
+
+	options := payload.Options.(*ChartOptions)
+	assert.Equal(ChartColumnsMapping{"day": "x", "revenue": "y"}, options.ColumnMapping)
+	assert.Len(options.SeriesOptions, 1)
+	assert.Equal("normal", *options.Series.Stacking)
+	assert.Equal("Revenue", options.YAxis[0].Title.Text)
+	assert.Nil(options.XAxis.Title)
+
+	table := NewTable().Query(12).Column("day", ColumnTitle("Day"))
+	tableOptions, err := table.Options()
+	assert.Nil(err)
+	table.Column("revenue")
+
+	assert.Len(tableOptions.Columns, 1)
+	rebuilt, _ := table.Options()
+	assert.Len(rebuilt.Columns, 2)
+}
@@ -0,0 +1,691 @@
+package redash
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Visualization types supported by Redash
+const (
+	VisualizationTypeTable      = "TABLE"
+	VisualizationTypeChart      = "CHART"
+	VisualizationTypeCounter    = "COUNTER"
+	VisualizationTypePivot      = "PIVOT"
+	VisualizationTypeCohort     = "COHORT"
+	VisualizationTypeFunnel     = "FUNNEL"
+	VisualizationTypeMap        = "MAP"
+	VisualizationTypeChoropleth = "CHOROPLETH"
+	VisualizationTypeSankey     = "SANKEY"
+	VisualizationTypeSunburst   = "SUNBURST_SEQUENCE"
+	VisualizationTypeWordCloud  = "WORD_CLOUD"
+	VisualizationTypeBoxPlot    = "BOXPLOT"
+	VisualizationTypeDetails    = "DETAILS"
+)
+
+// VisualizationOptions is implemented by every typed visualization options struct
+type VisualizationOptions interface {
+	VisualizationType() string
+}
+
+// COUNTER Options
+type CounterOptions struct {
+	CounterLabel      string `json:"counterLabel"`
+	CounterColName    string `json:"counterColName"`
+	RowNumber         int    `json:"rowNumber"`
+	TargetColName     string `json:"targetColName"`
+	TargetRowNumber   int    `json:"targetRowNumber"`
+	CountRow          bool   `json:"countRow"`
+	StringDecimal     int    `json:"stringDecimal"`
+	StringDecChar     string `json:"stringDecChar"`
+	StringThouSep     string `json:"stringThouSep"`
+	StringPrefix      string `json:"stringPrefix,omitempty"`
+	StringSuffix      string `json:"stringSuffix,omitempty"`
+	TooltipFormat     string `json:"tooltipFormat"`
+	FormatTargetValue bool   `json:"formatTargetValue"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// PIVOT Options
+type PivotOptions struct {
+	Controls        PivotControls          `json:"controls"`
+	RendererOptions map[string]interface{} `json:"rendererOptions,omitempty"`
+	RendererName    string                 `json:"rendererName,omitempty"`
+	AggregatorName  string                 `json:"aggregatorName,omitempty"`
+	Rows            []string               `json:"rows,omitempty"`
+	Cols            []string               `json:"cols,omitempty"`
+	Vals            []string               `json:"vals,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type PivotControls struct {
+	Enabled bool `json:"enabled"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// COHORT Options
+type CohortOptions struct {
+	TimeInterval       string            `json:"timeInterval"`
+	Mode               string            `json:"mode"`
+	DateColumn         string            `json:"dateColumn"`
+	StageColumn        string            `json:"stageColumn"`
+	TotalColumn        string            `json:"totalColumn"`
+	ValueColumn        string            `json:"valueColumn"`
+	NumberFormat       string            `json:"numberFormat,omitempty"`
+	PercentFormat      string            `json:"percentFormat,omitempty"`
+	NoValuePlaceholder string            `json:"noValuePlaceholder,omitempty"`
+	ShowTooltips       bool              `json:"showTooltips"`
+	PercentValues      bool              `json:"percentValues"`
+	Colors             map[string]string `json:"colors,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// FUNNEL Options
+type FunnelOptions struct {
+	StepCol            FunnelColumn       `json:"stepCol"`
+	ValueCol           FunnelColumn       `json:"valueCol"`
+	AutoSort           bool               `json:"autoSort"`
+	SortKeyCol         FunnelSortKey      `json:"sortKeyCol"`
+	ItemsLimit         int                `json:"itemsLimit"`
+	PercentValuesRange FunnelPercentRange `json:"percentValuesRange"`
+	NumberFormat       string             `json:"numberFormat,omitempty"`
+	PercentFormat      string             `json:"percentFormat,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type FunnelColumn struct {
+	ColName   string `json:"colName"`
+	DisplayAs string `json:"displayAs"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type FunnelSortKey struct {
+	ColName string `json:"colName"`
+	Reverse bool   `json:"reverse"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type FunnelPercentRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// MAP (markers) Options
+type MapOptions struct {
+	LatColName       string                   `json:"latColName"`
+	LonColName       string                   `json:"lonColName"`
+	Classify         string                   `json:"classify,omitempty"`
+	Groups           map[string]MapGroupStyle `json:"groups,omitempty"`
+	MapTileURL       string                   `json:"mapTileUrl,omitempty"`
+	ClusterMarkers   bool                     `json:"clusterMarkers"`
+	CustomizeMarkers bool                     `json:"customizeMarkers"`
+	IconShape        string                   `json:"iconShape,omitempty"`
+	IconFont         string                   `json:"iconFont,omitempty"`
+	ForegroundColor  string                   `json:"foregroundColor,omitempty"`
+	BackgroundColor  string                   `json:"backgroundColor,omitempty"`
+	BorderColor      string                   `json:"borderColor,omitempty"`
+	Bounds           interface{}              `json:"bounds,omitempty"`
+	Tooltip          VisualizationTemplate    `json:"tooltip"`
+	Popup            VisualizationTemplate    `json:"popup"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type MapGroupStyle struct {
+	Color string `json:"color"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// VisualizationTemplate configures tooltips and popups of map visualizations
+type VisualizationTemplate struct {
+	Enabled  bool   `json:"enabled"`
+	Template string `json:"template"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// CHOROPLETH Options
+type ChoroplethOptions struct {
+	MapType            string                `json:"mapType"`
+	KeyColumn          string                `json:"keyColumn"`
+	TargetField        string                `json:"targetField"`
+	ValueColumn        string                `json:"valueColumn"`
+	ClusteringMode     string                `json:"clusteringMode"`
+	Steps              int                   `json:"steps"`
+	ValueFormat        string                `json:"valueFormat,omitempty"`
+	NoValuePlaceholder string                `json:"noValuePlaceholder,omitempty"`
+	Legend             ChoroplethLegend      `json:"legend"`
+	Tooltip            VisualizationTemplate `json:"tooltip"`
+	Popup              VisualizationTemplate `json:"popup"`
+	Colors             ChoroplethColors      `json:"colors"`
+	Bounds             interface{}           `json:"bounds,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type ChoroplethLegend struct {
+	Visible   bool   `json:"visible"`
+	Position  string `json:"position,omitempty"`
+	AlignText string `json:"alignText,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type ChoroplethColors struct {
+	Min        string `json:"min,omitempty"`
+	Max        string `json:"max,omitempty"`
+	Background string `json:"background,omitempty"`
+	Borders    string `json:"borders,omitempty"`
+	NoValue    string `json:"noValue,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// SANKEY Options, Redash has no settings for this type so everything is kept in Extra
+type SankeyOptions struct {
+	Extra map[string]interface{} `json:"-"`
+}
+
+// SUNBURST_SEQUENCE Options, Redash has no settings for this type so everything is kept in Extra
+type SunburstOptions struct {
+	Extra map[string]interface{} `json:"-"`
+}
+
+// WORD_CLOUD Options
+type WordCloudOptions struct {
+	Column            string         `json:"column"`
+	FrequenciesColumn string         `json:"frequenciesColumn,omitempty"`
+	WordLengthLimit   WordCloudLimit `json:"wordLengthLimit"`
+	WordCountLimit    WordCloudLimit `json:"wordCountLimit"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type WordCloudLimit struct {
+	Min *int `json:"min"`
+	Max *int `json:"max"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// BOXPLOT Options
+type BoxPlotOptions struct {
+	XAxisLabel string `json:"xAxisLabel"`
+	YAxisLabel string `json:"yAxisLabel"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// DETAILS Options
+type DetailsOptions struct {
+	Columns []TableColumn `json:"columns,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (TableOptions) VisualizationType() string      { return VisualizationTypeTable }
+func (ChartOptions) VisualizationType() string      { return VisualizationTypeChart }
+func (CounterOptions) VisualizationType() string    { return VisualizationTypeCounter }
+func (PivotOptions) VisualizationType() string      { return VisualizationTypePivot }
+func (CohortOptions) VisualizationType() string     { return VisualizationTypeCohort }
+func (FunnelOptions) VisualizationType() string     { return VisualizationTypeFunnel }
+func (MapOptions) VisualizationType() string        { return VisualizationTypeMap }
+func (ChoroplethOptions) VisualizationType() string { return VisualizationTypeChoropleth }
+func (SankeyOptions) VisualizationType() string     { return VisualizationTypeSankey }
+func (SunburstOptions) VisualizationType() string   { return VisualizationTypeSunburst }
+func (WordCloudOptions) VisualizationType() string  { return VisualizationTypeWordCloud }
+func (BoxPlotOptions) VisualizationType() string    { return VisualizationTypeBoxPlot }
+func (DetailsOptions) VisualizationType() string    { return VisualizationTypeDetails }
+
+// NewVisualizationOptions returns an empty typed options struct for a visualization type
+func NewVisualizationOptions(visualizationType string) (VisualizationOptions, error) {
+	switch visualizationType {
+	case VisualizationTypeTable:
+		return &TableOptions{}, nil
+	case VisualizationTypeChart:
+		return &ChartOptions{}, nil
+	case VisualizationTypeCounter:
+		return &CounterOptions{}, nil
+	case VisualizationTypePivot:
+		return &PivotOptions{}, nil
+	case VisualizationTypeCohort:
+		return &CohortOptions{}, nil
+	case VisualizationTypeFunnel:
+		return &FunnelOptions{}, nil
+	case VisualizationTypeMap:
+		return &MapOptions{}, nil
+	case VisualizationTypeChoropleth:
+		return &ChoroplethOptions{}, nil
+	case VisualizationTypeSankey:
+		return &SankeyOptions{}, nil
+	case VisualizationTypeSunburst:
+		return &SunburstOptions{}, nil
+	case VisualizationTypeWordCloud:
+		return &WordCloudOptions{}, nil
+	case VisualizationTypeBoxPlot:
+		return &BoxPlotOptions{}, nil
+	case VisualizationTypeDetails:
+		return &DetailsOptions{}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported visualization type %q", visualizationType)
+}
+
+// DecodeVisualizationOptions decodes raw options into the typed struct matching the visualization type.
+// Fields the struct does not know about are kept in its Extra map and written back on encode.
+func DecodeVisualizationOptions(visualizationType string, options interface{}) (VisualizationOptions, error) {
+	decoded, err := NewVisualizationOptions(visualizationType)
+	if err != nil {
+		return nil, err
+	}
+
+	if options == nil {
+		return decoded, nil
+	}
+
+	raw, ok := options.(json.RawMessage)
+	if !ok {
+		raw, err = json.Marshal(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = json.Unmarshal(raw, decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// DecodeOptions decodes Options into the typed struct matching Type
+func (v *VisualizationQuery) DecodeOptions() (VisualizationOptions, error) {
+	return DecodeVisualizationOptions(v.Type, v.Options)
+}
+
+// DecodeOptions decodes Options into the typed struct matching Type
+func (v *VisualizationDashboard) DecodeOptions() (VisualizationOptions, error) {
+	return DecodeVisualizationOptions(v.Type, v.Options)
+}
+
+// unmarshalOptions decodes data into v and collects the keys v has no field for into extra
+func unmarshalOptions(data []byte, v interface{}, extra *map[string]interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	for _, name := range jsonFieldNames(reflect.TypeOf(v).Elem()) {
+		delete(fields, name)
+	}
+
+	*extra = nil
+	if len(fields) > 0 {
+		*extra = fields
+	}
+
+	return nil
+}
+
+// marshalOptions encodes v and merges back the keys collected by unmarshalOptions
+func marshalOptions(v interface{}, extra map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for name, value := range extra {
+		if _, known := fields[name]; !known {
+			fields[name] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+func jsonFieldNames(t reflect.Type) []string {
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" || field.PkgPath != "" {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		names = append(names, tag)
+	}
+	return names
+}
+
+func (o *TableOptions) UnmarshalJSON(data []byte) error {
+	type plain TableOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o TableOptions) MarshalJSON() ([]byte, error) {
+	type plain TableOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *TableColumn) UnmarshalJSON(data []byte) error {
+	type plain TableColumn
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o TableColumn) MarshalJSON() ([]byte, error) {
+	type plain TableColumn
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChartOptions) UnmarshalJSON(data []byte) error {
+	type plain ChartOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChartOptions) MarshalJSON() ([]byte, error) {
+	type plain ChartOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChartLegend) UnmarshalJSON(data []byte) error {
+	type plain ChartLegend
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChartLegend) MarshalJSON() ([]byte, error) {
+	type plain ChartLegend
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChartXAxis) UnmarshalJSON(data []byte) error {
+	type plain ChartXAxis
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChartXAxis) MarshalJSON() ([]byte, error) {
+	type plain ChartXAxis
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChartYAxis) UnmarshalJSON(data []byte) error {
+	type plain ChartYAxis
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChartYAxis) MarshalJSON() ([]byte, error) {
+	type plain ChartYAxis
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChartSeriesOption) UnmarshalJSON(data []byte) error {
+	type plain ChartSeriesOption
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChartSeriesOption) MarshalJSON() ([]byte, error) {
+	type plain ChartSeriesOption
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChartSeries) UnmarshalJSON(data []byte) error {
+	type plain ChartSeries
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChartSeries) MarshalJSON() ([]byte, error) {
+	type plain ChartSeries
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChartAxisLabels) UnmarshalJSON(data []byte) error {
+	type plain ChartAxisLabels
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChartAxisLabels) MarshalJSON() ([]byte, error) {
+	type plain ChartAxisLabels
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChartAxisTitle) UnmarshalJSON(data []byte) error {
+	type plain ChartAxisTitle
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChartAxisTitle) MarshalJSON() ([]byte, error) {
+	type plain ChartAxisTitle
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChartErrorY) UnmarshalJSON(data []byte) error {
+	type plain ChartErrorY
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChartErrorY) MarshalJSON() ([]byte, error) {
+	type plain ChartErrorY
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *CounterOptions) UnmarshalJSON(data []byte) error {
+	type plain CounterOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o CounterOptions) MarshalJSON() ([]byte, error) {
+	type plain CounterOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *PivotOptions) UnmarshalJSON(data []byte) error {
+	type plain PivotOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o PivotOptions) MarshalJSON() ([]byte, error) {
+	type plain PivotOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *PivotControls) UnmarshalJSON(data []byte) error {
+	type plain PivotControls
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o PivotControls) MarshalJSON() ([]byte, error) {
+	type plain PivotControls
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *CohortOptions) UnmarshalJSON(data []byte) error {
+	type plain CohortOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o CohortOptions) MarshalJSON() ([]byte, error) {
+	type plain CohortOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *FunnelOptions) UnmarshalJSON(data []byte) error {
+	type plain FunnelOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o FunnelOptions) MarshalJSON() ([]byte, error) {
+	type plain FunnelOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *FunnelColumn) UnmarshalJSON(data []byte) error {
+	type plain FunnelColumn
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o FunnelColumn) MarshalJSON() ([]byte, error) {
+	type plain FunnelColumn
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *FunnelSortKey) UnmarshalJSON(data []byte) error {
+	type plain FunnelSortKey
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o FunnelSortKey) MarshalJSON() ([]byte, error) {
+	type plain FunnelSortKey
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *FunnelPercentRange) UnmarshalJSON(data []byte) error {
+	type plain FunnelPercentRange
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o FunnelPercentRange) MarshalJSON() ([]byte, error) {
+	type plain FunnelPercentRange
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *MapOptions) UnmarshalJSON(data []byte) error {
+	type plain MapOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o MapOptions) MarshalJSON() ([]byte, error) {
+	type plain MapOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *MapGroupStyle) UnmarshalJSON(data []byte) error {
+	type plain MapGroupStyle
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o MapGroupStyle) MarshalJSON() ([]byte, error) {
+	type plain MapGroupStyle
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *VisualizationTemplate) UnmarshalJSON(data []byte) error {
+	type plain VisualizationTemplate
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o VisualizationTemplate) MarshalJSON() ([]byte, error) {
+	type plain VisualizationTemplate
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChoroplethOptions) UnmarshalJSON(data []byte) error {
+	type plain ChoroplethOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChoroplethOptions) MarshalJSON() ([]byte, error) {
+	type plain ChoroplethOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChoroplethLegend) UnmarshalJSON(data []byte) error {
+	type plain ChoroplethLegend
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChoroplethLegend) MarshalJSON() ([]byte, error) {
+	type plain ChoroplethLegend
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *ChoroplethColors) UnmarshalJSON(data []byte) error {
+	type plain ChoroplethColors
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o ChoroplethColors) MarshalJSON() ([]byte, error) {
+	type plain ChoroplethColors
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *SankeyOptions) UnmarshalJSON(data []byte) error {
+	type plain SankeyOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o SankeyOptions) MarshalJSON() ([]byte, error) {
+	type plain SankeyOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *SunburstOptions) UnmarshalJSON(data []byte) error {
+	type plain SunburstOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o SunburstOptions) MarshalJSON() ([]byte, error) {
+	type plain SunburstOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *WordCloudOptions) UnmarshalJSON(data []byte) error {
+	type plain WordCloudOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o WordCloudOptions) MarshalJSON() ([]byte, error) {
+	type plain WordCloudOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *WordCloudLimit) UnmarshalJSON(data []byte) error {
+	type plain WordCloudLimit
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o WordCloudLimit) MarshalJSON() ([]byte, error) {
+	type plain WordCloudLimit
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *BoxPlotOptions) UnmarshalJSON(data []byte) error {
+	type plain BoxPlotOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o BoxPlotOptions) MarshalJSON() ([]byte, error) {
+	type plain BoxPlotOptions
+	return marshalOptions(plain(o), o.Extra)
+}
+
+func (o *DetailsOptions) UnmarshalJSON(data []byte) error {
+	type plain DetailsOptions
+	return unmarshalOptions(data, (*plain)(o), &o.Extra)
+}
+
+func (o DetailsOptions) MarshalJSON() ([]byte, error) {
+	type plain DetailsOptions
+	return marshalOptions(plain(o), o.Extra)
+}
@@ -0,0 +1,159 @@
+package redash
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	body, err := ioutil.ReadFile("testdata/get-dashboard.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	dashboard := Dashboard{}
+	if err := json.Unmarshal(body, &dashboard); err != nil {
+		panic(err.Error())
+	}
+
+	visualization := dashboard.Widgets[0].Visualization
+	decoded, err := visualization.DecodeOptions()
+	assert.Nil(err)
+
+	options, ok := decoded.(*ChartOptions)
+	assert.True(ok)
+	assert.Equal("line", options.GlobalSeriesType)
+	assert.Equal("auto", options.Legend.Extra["placement"])
+	assert.Equal("Cardinality", options.YAxis[0].Title.Text)
+
+	// Every field Redash sent survives a decode/encode round trip, typed
+	// fields are written with their zero value when they were absent
+	encoded, err := json.Marshal(options)
+	assert.Nil(err)
+	original := visualization.Options.(map[string]interface{})
+	original["yAxis"].([]interface{})[0].(map[string]interface{})["opposite"] = false
+	expected, _ := json.Marshal(original)
+	assert.JSONEq(string(expected), string(encoded))
+}
+
+func TestDecodeOptionsTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	visualization := VisualizationQuery{
+		Type: VisualizationTypeCounter,
+		Options: map[string]interface{}{
+			"counterColName": "value",
+			"rowNumber":      1,
+			"stringDecimal":  2,
+			"someNewSetting": true,
+		},
+	}
+	decoded, err := visualization.DecodeOptions()
+	assert.Nil(err)
+	counter := decoded.(*CounterOptions)
+	assert.Equal("value", counter.CounterColName)
+	assert.Equal(2, counter.StringDecimal)
+	assert.Equal(true, counter.Extra["someNewSetting"])
+
+	visualization = VisualizationQuery{
+		Type:    VisualizationTypeFunnel,
+		Options: map[string]interface{}{"stepCol": map[string]interface{}{"colName": "step", "displayAs": "Step"}, "itemsLimit": 100},
+	}
+	decoded, err = visualization.DecodeOptions()
+	assert.Nil(err)
+	funnel := decoded.(*FunnelOptions)
+	assert.Equal("step", funnel.StepCol.ColName)
+	assert.Equal(100, funnel.ItemsLimit)
+	assert.Nil(funnel.Extra)
+
+	visualization = VisualizationQuery{Type: VisualizationTypeSankey, Options: map[string]interface{}{"anything": "kept"}}
+	decoded, err = visualization.DecodeOptions()
+	assert.Nil(err)
+	encoded, _ := json.Marshal(decoded)
+	assert.JSONEq(`{"anything": "kept"}`, string(encoded))
+
+	types := []string{
+		VisualizationTypeTable, VisualizationTypeChart, VisualizationTypeCounter, VisualizationTypePivot,
+		VisualizationTypeCohort, VisualizationTypeFunnel, VisualizationTypeMap, VisualizationTypeChoropleth,
+		VisualizationTypeSankey, VisualizationTypeSunburst, VisualizationTypeWordCloud, VisualizationTypeBoxPlot,
+		VisualizationTypeDetails,
+	}
+	for _, visualizationType := range types {
+		options, err := NewVisualizationOptions(visualizationType)
+		assert.Nil(err)
+		assert.Equal(visualizationType, options.VisualizationType())
+	}
+
+	_, err = DecodeVisualizationOptions("UNKNOWN", nil)
+	assert.NotNil(err)
+}
+
+func TestDecodeOptionsNestedExtra(t *testing.T) {
+	assert := assert.New(t)
+
+	// Settings Redash adds to nested objects survive a round trip as well
+	optionsByType := map[string]string{
+		VisualizationTypeChart: `{
+			"series": {"stacking": null, "error_y": {"visible": true, "type": "data", "symmetric": false}, "percentValues": true},
+			"xAxis": {"type": "-", "labels": {"enabled": true, "rotation": 45}, "title": {"text": "Day", "font": "bold"}}
+		}`,
+		VisualizationTypeFunnel: `{
+			"stepCol": {"colName": "step", "displayAs": "Step", "width": 10},
+			"sortKeyCol": {"colName": "value", "reverse": true, "nulls": "last"},
+			"percentValuesRange": {"min": 0.01, "max": 1000, "clamp": true}
+		}`,
+		VisualizationTypeMap: `{
+			"groups": {"EU": {"color": "#356AFF", "opacity": 0.5}},
+			"tooltip": {"enabled": true, "template": "{{ name }}", "sticky": true}
+		}`,
+		VisualizationTypeChoropleth: `{
+			"legend": {"visible": true, "position": "bottom-left", "traceorder": "normal"},
+			"colors": {"min": "#fff", "max": "#000", "gradient": "linear"}
+		}`,
+		VisualizationTypePivot:     `{"controls": {"enabled": true, "compact": true}}`,
+		VisualizationTypeWordCloud: `{"column": "word", "wordLengthLimit": {"min": 2, "max": null, "unit": "chars"}}`,
+	}
+
+	for visualizationType, body := range optionsByType {
+		var options map[string]interface{}
+		assert.Nil(json.Unmarshal([]byte(body), &options))
+
+		decoded, err := DecodeVisualizationOptions(visualizationType, options)
+		assert.Nil(err, visualizationType)
+
+		encoded, err := json.Marshal(decoded)
+		assert.Nil(err, visualizationType)
+		var roundTripped map[string]interface{}
+		assert.Nil(json.Unmarshal(encoded, &roundTripped))
+
+		// Only the unknown nested keys are compared, typed fields absent from
+		// the input are written back with their zero value
+		assertContainsJSON(t, options, roundTripped, visualizationType)
+	}
+
+	decoded, _ := DecodeVisualizationOptions(VisualizationTypeChart, map[string]interface{}{
+		"xAxis": map[string]interface{}{"labels": map[string]interface{}{"enabled": true, "rotation": 45}},
+	})
+	assert.Equal(float64(45), decoded.(*ChartOptions).XAxis.Labels.Extra["rotation"])
+}
+
+// assertContainsJSON asserts that every key of expected is found with the same value in actual
+func assertContainsJSON(t *testing.T, expected, actual interface{}, path string) {
+	expectedMap, ok := expected.(map[string]interface{})
+	if !ok {
+		assert.Equal(t, expected, actual, path)
+		return
+	}
+
+	actualMap, ok := actual.(map[string]interface{})
+	if !assert.True(t, ok, path) {
+		return
+	}
+	for key, value := range expectedMap {
+		assertContainsJSON(t, value, actualMap[key], path+"."+key)
+	}
+}
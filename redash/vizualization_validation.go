@@ -0,0 +1,256 @@
+package redash
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of VisualizationIssue
+const (
+	IssueMissingColumn    = "missing"
+	IssueMisspelledColumn = "misspelled"
+	IssueIncompatibleType = "incompatible_type"
+)
+
+// VisualizationIssue describes an option referencing a column the query result cannot satisfy
+type VisualizationIssue struct {
+	Kind       string
+	Field      string
+	Column     string
+	ColumnType string
+	Suggestion string
+}
+
+func (i VisualizationIssue) String() string {
+	switch i.Kind {
+	case IssueMisspelledColumn:
+		return fmt.Sprintf("%s: column %q does not exist, did you mean %q?", i.Field, i.Column, i.Suggestion)
+	case IssueIncompatibleType:
+		return fmt.Sprintf("%s: column %q has incompatible type %s", i.Field, i.Column, i.ColumnType)
+	default:
+		return fmt.Sprintf("%s: column %q does not exist", i.Field, i.Column)
+	}
+}
+
+// VisualizationValidation lists the issues found by ValidateVisualizationOptions
+type VisualizationValidation struct {
+	Issues []VisualizationIssue
+}
+
+// Valid returns true if no issues were found
+func (v *VisualizationValidation) Valid() bool {
+	return len(v.Issues) == 0
+}
+
+// Err returns an error listing every issue, or nil if the visualization is valid
+func (v *VisualizationValidation) Err() error {
+	if v.Valid() {
+		return nil
+	}
+
+	messages := []string{}
+	for _, issue := range v.Issues {
+		messages = append(messages, issue.String())
+	}
+	return fmt.Errorf("invalid visualization options: %s", strings.Join(messages, "; "))
+}
+
+var (
+	numericColumnTypes  = []string{ColumnTypeInteger, ColumnTypeFloat}
+	datetimeColumnTypes = []string{ColumnTypeDatetime, ColumnTypeDate, ColumnTypeString}
+	booleanColumnTypes  = []string{ColumnTypeBoolean, ColumnTypeInteger}
+)
+
+type columnValidator struct {
+	columns    map[string]QueryResultColumn
+	validation *VisualizationValidation
+}
+
+// check records an issue if column is not one of the result columns or has a type outside of allowed.
+// An empty column is an unset option and always passes, so does a column Redash did not detect a type for.
+func (v *columnValidator) check(field, column string, allowed []string) {
+	if column == "" {
+		return
+	}
+
+	resultColumn, exists := v.columns[column]
+	if !exists {
+		issue := VisualizationIssue{Kind: IssueMissingColumn, Field: field, Column: column}
+		if suggestion := v.suggest(column); suggestion != "" {
+			issue.Kind = IssueMisspelledColumn
+			issue.Suggestion = suggestion
+		}
+		v.validation.Issues = append(v.validation.Issues, issue)
+		return
+	}
+
+	if len(allowed) == 0 || resultColumn.Type == "" {
+		return
+	}
+	for _, columnType := range allowed {
+		if resultColumn.Type == columnType {
+			return
+		}
+	}
+	v.validation.Issues = append(v.validation.Issues, VisualizationIssue{
+		Kind:       IssueIncompatibleType,
+		Field:      field,
+		Column:     column,
+		ColumnType: resultColumn.Type,
+	})
+}
+
+// suggest returns the result column closest to name, if it is close enough to be a typo
+func (v *columnValidator) suggest(name string) string {
+	best, bestDistance := "", -1
+	for candidate := range v.columns {
+		if strings.EqualFold(candidate, name) {
+			return candidate
+		}
+
+		distance := levenshtein(strings.ToLower(candidate), strings.ToLower(name))
+		if distance > 2 || distance*2 >= len(name) {
+			continue
+		}
+		if bestDistance < 0 || distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous = current
+	}
+
+	return previous[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (v *columnValidator) checkTableColumns(columns []TableColumn) {
+	for i, column := range columns {
+		field := fmt.Sprintf("columns[%d].name", i)
+		switch column.DisplayAs {
+		case "number":
+			v.check(field, column.Name, numericColumnTypes)
+		case "datetime":
+			v.check(field, column.Name, datetimeColumnTypes)
+		case "boolean":
+			v.check(field, column.Name, booleanColumnTypes)
+		default:
+			v.check(field, column.Name, nil)
+		}
+	}
+}
+
+// ValidateVisualizationOptions checks that every column referenced by the options of a
+// visualization exists in columns and has a type the visualization can render
+func ValidateVisualizationOptions(visualizationType string, options interface{}, columns []QueryResultColumn) (*VisualizationValidation, error) {
+	decoded, err := DecodeVisualizationOptions(visualizationType, options)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &columnValidator{
+		columns:    map[string]QueryResultColumn{},
+		validation: &VisualizationValidation{},
+	}
+	for _, column := range columns {
+		v.columns[column.Name] = column
+	}
+
+	switch o := decoded.(type) {
+	case *ChartOptions:
+		names := []string{}
+		for column := range o.ColumnMapping {
+			names = append(names, column)
+		}
+		sort.Strings(names)
+
+		for _, column := range names {
+			field := "columnMapping." + column
+			switch o.ColumnMapping[column] {
+			case ChartColumnY, ChartColumnSize, ChartColumnYError:
+				v.check(field, column, numericColumnTypes)
+			default:
+				v.check(field, column, nil)
+			}
+		}
+	case *TableOptions:
+		v.checkTableColumns(o.Columns)
+	case *DetailsOptions:
+		v.checkTableColumns(o.Columns)
+	case *CounterOptions:
+		v.check("counterColName", o.CounterColName, nil)
+		v.check("targetColName", o.TargetColName, nil)
+	case *PivotOptions:
+		for i, column := range o.Rows {
+			v.check(fmt.Sprintf("rows[%d]", i), column, nil)
+		}
+		for i, column := range o.Cols {
+			v.check(fmt.Sprintf("cols[%d]", i), column, nil)
+		}
+		for i, column := range o.Vals {
+			v.check(fmt.Sprintf("vals[%d]", i), column, nil)
+		}
+	case *CohortOptions:
+		v.check("dateColumn", o.DateColumn, datetimeColumnTypes)
+		v.check("stageColumn", o.StageColumn, nil)
+		v.check("totalColumn", o.TotalColumn, numericColumnTypes)
+		v.check("valueColumn", o.ValueColumn, numericColumnTypes)
+	case *FunnelOptions:
+		v.check("stepCol.colName", o.StepCol.ColName, nil)
+		v.check("valueCol.colName", o.ValueCol.ColName, numericColumnTypes)
+		v.check("sortKeyCol.colName", o.SortKeyCol.ColName, nil)
+	case *MapOptions:
+		v.check("latColName", o.LatColName, numericColumnTypes)
+		v.check("lonColName", o.LonColName, numericColumnTypes)
+		v.check("classify", o.Classify, nil)
+	case *ChoroplethOptions:
+		v.check("keyColumn", o.KeyColumn, nil)
+		v.check("valueColumn", o.ValueColumn, numericColumnTypes)
+	case *WordCloudOptions:
+		v.check("column", o.Column, nil)
+		v.check("frequenciesColumn", o.FrequenciesColumn, numericColumnTypes)
+	}
+
+	return v.validation, nil
+}
+
+// ValidateVisualization checks visualization options against the columns of the latest result of a query
+func (c *Client) ValidateVisualization(queryId int, visualizationType string, options interface{}) (*VisualizationValidation, error) {
+	result, err := c.GetLatestQueryResult(queryId)
+	if err != nil {
+		return nil, err
+	}
+
+	return ValidateVisualizationOptions(visualizationType, options, result.Data.Columns)
+}
+
+// ValidateVisualizationCreatePayload checks a VisualizationCreatePayload against the latest result of its query
+func (c *Client) ValidateVisualizationCreatePayload(payload *VisualizationCreatePayload) (*VisualizationValidation, error) {
+	return c.ValidateVisualization(payload.QueryId, payload.Type, payload.Options)
+}
@@ -0,0 +1,101 @@
+package redash
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var salesColumns = []QueryResultColumn{
+	{Name: "day", Type: ColumnTypeDate},
+	{Name: "revenue", Type: ColumnTypeFloat},
+	{Name: "orders", Type: ColumnTypeInteger},
+	{Name: "country", Type: ColumnTypeString},
+}
+
+func TestValidateChartOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	options, _ := NewLineChart().X("day").Y("revenue").Y("Orders").Y("country").Y("profit").Options()
+	validation, err := ValidateVisualizationOptions(VisualizationTypeChart, options, salesColumns)
+	assert.Nil(err)
+
+	assert.False(validation.Valid())
+	assert.NotNil(validation.Err())
+	assert.Equal([]VisualizationIssue{
+		{Kind: IssueMisspelledColumn, Field: "columnMapping.Orders", Column: "Orders", Suggestion: "orders"},
+		{Kind: IssueIncompatibleType, Field: "columnMapping.country", Column: "country", ColumnType: ColumnTypeString},
+		{Kind: IssueMissingColumn, Field: "columnMapping.profit", Column: "profit"},
+	}, validation.Issues)
+
+	options, _ = NewLineChart().X("day").Y("revenue").GroupBy("country").Options()
+	validation, err = ValidateVisualizationOptions(VisualizationTypeChart, options, salesColumns)
+	assert.Nil(err)
+	assert.True(validation.Valid())
+	assert.Nil(validation.Err())
+}
+
+func TestValidateTableOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	options, _ := NewTable().
+		Column("day", ColumnDateTime("YYYY-MM-DD")).
+		Column("revenu", ColumnNumber("0.00")).
+		Column("country", ColumnNumber("0")).
+		Options()
+	validation, err := ValidateVisualizationOptions(VisualizationTypeTable, options, salesColumns)
+	assert.Nil(err)
+
+	assert.Equal(2, len(validation.Issues))
+	assert.Equal(IssueMisspelledColumn, validation.Issues[0].Kind)
+	assert.Equal("revenue", validation.Issues[0].Suggestion)
+	assert.Equal("columns[1].name", validation.Issues[0].Field)
+	assert.Equal(IssueIncompatibleType, validation.Issues[1].Kind)
+}
+
+func TestValidateOtherOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	validation, err := ValidateVisualizationOptions(VisualizationTypeCounter,
+		map[string]interface{}{"counterColName": "revenue", "targetColName": "target"}, salesColumns)
+	assert.Nil(err)
+	assert.Equal(1, len(validation.Issues))
+	assert.Equal(IssueMissingColumn, validation.Issues[0].Kind)
+
+	validation, err = ValidateVisualizationOptions(VisualizationTypeFunnel,
+		&FunnelOptions{StepCol: FunnelColumn{ColName: "country"}, ValueCol: FunnelColumn{ColName: "orders"}}, salesColumns)
+	assert.Nil(err)
+	assert.True(validation.Valid())
+
+	_, err = ValidateVisualizationOptions("UNKNOWN", nil, salesColumns)
+	assert.NotNil(err)
+}
+
+func TestValidateVisualization(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	query, err := ioutil.ReadFile("testdata/get-query.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/1",
+		httpmock.NewStringResponder(200, string(query)))
+
+	body, err := ioutil.ReadFile("testdata/get-query-result.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/query_results/3919563",
+		httpmock.NewStringResponder(200, string(body)))
+
+	payload, _ := NewBarChart().Query(1).X("day").Y("revenue").Build()
+	validation, err := c.ValidateVisualizationCreatePayload(payload)
+	assert.Nil(err)
+	assert.True(validation.Valid())
+}
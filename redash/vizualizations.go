@@ -47,6 +47,8 @@ type VisualizationDashboard struct {
 type TableOptions struct {
 	ItemsPerPage int           `json:"itemsPerPage"`
 	Columns      []TableColumn `json:"columns"`
+
+	Extra map[string]interface{} `json:"-"`
 }
 
 type TableColumn struct {
@@ -80,6 +82,8 @@ type TableColumn struct {
 	ImageWidth         string `json:"imageWidth"`
 	ImageHeight        string `json:"imageHeight"`
 	// JSON
+
+	Extra map[string]interface{} `json:"-"`
 }
 
 // CHART Options
@@ -113,6 +117,8 @@ type ChartOptions struct {
 	// },
 	// "valuesOptions": {},
 	// "customCode": "// Available variables are x, ys, element, and Plotly\n// Type console.log(x, ys); for more info about x and ys\n// To plot your graph call Plotly.plot(element, ...)\n// Plotly examples and docs: https://plot.ly/javascript/",
+
+	Extra map[string]interface{} `json:"-"`
 }
 
 type ChartColumnsMapping map[string]string
@@ -120,28 +126,50 @@ type ChartColumnsMapping map[string]string
 type ChartLegend struct {
 	Enabled bool `json:"enabled"`
 	// Placement string `json:"placement"`
+
+	Extra map[string]interface{} `json:"-"`
 }
 
 type ChartSeries struct {
 	Stacking *string     `json:"stacking"`
 	ErrorY   ChartErrorY `json:"error_y"`
+
+	Extra map[string]interface{} `json:"-"`
 }
 
 type ChartXAxis struct {
-	Type   string `json:"type"`
-	Labels struct {
-		Enabled bool `json:"enabled"`
-	} `json:"labels"`
+	Type   string          `json:"type"`
+	Labels ChartAxisLabels `json:"labels"`
+	Title  *ChartAxisTitle `json:"title,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type ChartAxisLabels struct {
+	Enabled bool `json:"enabled"`
+
+	Extra map[string]interface{} `json:"-"`
 }
 
 type ChartYAxis struct {
-	Type     string `json:"type"`
-	Opposite bool   `json:"opposite"`
+	Type     string          `json:"type"`
+	Opposite bool            `json:"opposite"`
+	Title    *ChartAxisTitle `json:"title,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type ChartAxisTitle struct {
+	Text string `json:"text"`
+
+	Extra map[string]interface{} `json:"-"`
 }
 
 type ChartErrorY struct {
 	Visible bool   `json:"visible"`
 	Type    string `json:"type"`
+
+	Extra map[string]interface{} `json:"-"`
 }
 
 type ChartSeriesOptions map[string]ChartSeriesOption
@@ -151,6 +179,10 @@ type ChartSeriesOption struct {
 	Index  int    `json:"index"`
 	Type   string `json:"type"`
 	YAxis  int    `json:"yAxis"`
+	Name   string `json:"name,omitempty"`
+	Color  string `json:"color,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
 }
 
 // VisualizationCreatePayload defines the schema for creating a Redash visualizations
@@ -84,6 +84,20 @@ type WidgetUpdatePayload struct {
 	Options WidgetOptions `json:"options"`
 }
 
+// IsText returns true for text widgets, which have no visualization
+func (w *WidgetDashboard) IsText() bool {
+	return w.Visualization.ID == 0
+}
+
+// TextWidgetOptions returns the options of a visible text widget at the given position
+func TextWidgetOptions(position WidgetPosition) WidgetOptions {
+	return WidgetOptions{
+		IsHidden:          false,
+		ParameterMappings: map[string]WidgetParameterMapping{},
+		Position:          position,
+	}
+}
+
 // GetWidget returns a specific Widget by its dashboard slug and widget ID
 func (c *Client) GetWidget(dashboardSlug string, widgetId int) (*WidgetDashboard, error) {
 	dashboard, err := c.GetDashboard(dashboardSlug)
@@ -158,3 +172,24 @@ func (c *Client) DeleteWidget(id int) error {
 
 	return err
 }
+
+// CreateTextWidget creates a new Redash text widget rendering the given markdown
+func (c *Client) CreateTextWidget(dashboardId int, text string, position WidgetPosition) (*WidgetDashboard, error) {
+	return c.CreateWidget(&WidgetCreatePayload{
+		DashboardID:     dashboardId,
+		Text:            text,
+		Width:           1,
+		VisualizationID: nil,
+		Options:         TextWidgetOptions(position),
+	})
+}
+
+// UpdateTextWidget updates the markdown and position of an existing Redash text widget
+func (c *Client) UpdateTextWidget(id int, text string, position WidgetPosition) (*WidgetDashboard, error) {
+	return c.UpdateWidget(id, &WidgetUpdatePayload{
+		Text:            text,
+		Width:           1,
+		VisualizationID: nil,
+		Options:         TextWidgetOptions(position),
+	})
+}
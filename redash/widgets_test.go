@@ -1,9 +1,11 @@
 package redash
 
 import (
+	"encoding/json"
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
+	"net/http"
 	"testing"
 )
 
@@ -155,3 +157,42 @@ func TestDeleteWidget(t *testing.T) {
 	err := c.DeleteWidget(112)
 	assert.Nil(err)
 }
+
+func TestCreateTextWidget(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	var payload map[string]interface{}
+	httpmock.RegisterResponder("POST", "https://com.acme/api/widgets",
+		func(request *http.Request) (*http.Response, error) {
+			json.NewDecoder(request.Body).Decode(&payload)
+			return httpmock.NewStringResponse(200, `{"id": 7, "dashboard_id": 5, "text": "## Revenue", "width": 1}`), nil
+		})
+
+	widget, err := c.CreateTextWidget(5, "## Revenue", WidgetPosition{SizeX: 6, SizeY: 2})
+	assert.Nil(err)
+
+	assert.Nil(payload["visualization_id"])
+	assert.Contains(payload, "visualization_id")
+	assert.Equal("## Revenue", payload["text"])
+	assert.Equal(7, widget.ID)
+	assert.True(widget.IsText())
+}
+
+func TestUpdateTextWidget(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/widgets/7",
+		httpmock.NewStringResponder(200, `{"id": 7, "dashboard_id": 5, "text": "## Orders", "width": 1}`))
+
+	widget, err := c.UpdateTextWidget(7, "## Orders", WidgetPosition{SizeX: 6, SizeY: 2})
+	assert.Nil(err)
+	assert.Equal("## Orders", widget.Text)
+}